@@ -1,6 +1,7 @@
 package balancers
 
 import (
+	"cmp"
 	"container/list"
 	"fmt"
 	"math/rand/v2"
@@ -67,15 +68,15 @@ func (w *Lottery) Success(key uint) {
 	w.success = key
 }
 
-// 按顺序循环轮转，每次降低权重后移到队尾
-type Rotor struct {
+// 按权重从高到低依次尝试，限流后移到队尾
+type Priority struct {
 	*list.List
 	success uint
 	fails   map[uint]struct{}
 	reduces map[uint]struct{}
 }
 
-func NewRotor(items map[uint]int) *Rotor {
+func NewPriority(items map[uint]int) *Priority {
 	l := list.New()
 	entries := lo.Entries(items)
 	slices.SortFunc(entries, func(a lo.Entry[uint, int], b lo.Entry[uint, int]) int {
@@ -84,14 +85,14 @@ func NewRotor(items map[uint]int) *Rotor {
 	for _, entry := range entries {
 		l.PushBack(entry.Key)
 	}
-	return &Rotor{
+	return &Priority{
 		List:    l,
 		fails:   map[uint]struct{}{},
 		reduces: map[uint]struct{}{},
 	}
 }
 
-func (w *Rotor) Pop() (uint, error) {
+func (w *Priority) Pop() (uint, error) {
 	if w.Len() == 0 {
 		return 0, fmt.Errorf("no provide items")
 	}
@@ -99,7 +100,7 @@ func (w *Rotor) Pop() (uint, error) {
 	return e.Value.(uint), nil
 }
 
-func (w *Rotor) Delete(key uint) {
+func (w *Priority) Delete(key uint) {
 	w.fails[key] = struct{}{}
 	for e := w.Front(); e != nil; e = e.Next() {
 		if e.Value.(uint) == key {
@@ -109,7 +110,7 @@ func (w *Rotor) Delete(key uint) {
 	}
 }
 
-func (w *Rotor) Reduce(key uint) {
+func (w *Priority) Reduce(key uint) {
 	w.reduces[key] = struct{}{}
 	for e := w.Front(); e != nil; e = e.Next() {
 		if e.Value.(uint) == key {
@@ -119,6 +120,53 @@ func (w *Rotor) Reduce(key uint) {
 	}
 }
 
-func (w *Rotor) Success(key uint) {
+func (w *Priority) Success(key uint) {
 	w.success = key
 }
+
+// Price 关联配置的 token 单价。
+// 比较时输入与输出单价按 1:1 相加，不考虑实际输入输出比例；缓存单价仅在两者之和相同时用于排序
+type Price struct {
+	Input  float64
+	Output float64
+	Cached float64
+}
+
+// priced 是否配置了输入或输出单价
+func (p Price) priced() bool {
+	return p.Input > 0 || p.Output > 0
+}
+
+// NewCheapest 按价格从低到高依次尝试，输入与输出单价之和相同时比较缓存单价，再按权重从高到低；
+// 未配置价格的关联排在最后，失败或限流后的行为与 Priority 一致
+func NewCheapest(items map[uint]int, prices map[uint]Price) *Priority {
+	keys := lo.Keys(items)
+	slices.SortFunc(keys, func(a, b uint) int {
+		pa, pb := prices[a], prices[b]
+		if pa.priced() != pb.priced() {
+			if pa.priced() {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(pa.Input+pa.Output, pb.Input+pb.Output); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(pa.Cached, pb.Cached); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(items[b], items[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	l := list.New()
+	for _, key := range keys {
+		l.PushBack(key)
+	}
+	return &Priority{
+		List:    l,
+		fails:   map[uint]struct{}{},
+		reduces: map[uint]struct{}{},
+	}
+}
@@ -39,14 +39,14 @@ func TestLotteryDelete(t *testing.T) {
 	}
 }
 
-func TestRotor(t *testing.T) {
-	t.Run("NewRotor", func(t *testing.T) {
+func TestPriority(t *testing.T) {
+	t.Run("NewPriority", func(t *testing.T) {
 		items := map[uint]int{
 			1: 10,
 			2: 20,
 			3: 30,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		if wl.Len() != 3 {
 			t.Errorf("Expected length 3, got %d", wl.Len())
@@ -59,7 +59,7 @@ func TestRotor(t *testing.T) {
 			2: 20,
 			3: 30,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		// Should return the item with highest weight (3)
 		result, err := wl.Pop()
@@ -72,7 +72,7 @@ func TestRotor(t *testing.T) {
 	})
 
 	t.Run("Pop empty list", func(t *testing.T) {
-		wl := NewRotor(map[uint]int{})
+		wl := NewPriority(map[uint]int{})
 		_, err := wl.Pop()
 		if err == nil {
 			t.Error("Expected error when popping from empty list")
@@ -85,7 +85,7 @@ func TestRotor(t *testing.T) {
 			2: 20,
 			3: 30,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		wl.Delete(2)
 		if wl.Len() != 2 {
@@ -110,7 +110,7 @@ func TestRotor(t *testing.T) {
 			1: 10,
 			2: 20,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		originalLen := wl.Len()
 		wl.Delete(999) // Non-existent item
@@ -126,7 +126,7 @@ func TestRotor(t *testing.T) {
 			2: 20,
 			3: 30,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		// Reduce item 3 (highest weight)
 		wl.Reduce(3)
@@ -143,7 +143,7 @@ func TestRotor(t *testing.T) {
 			1: 10,
 			2: 20,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		originalLen := wl.Len()
 		wl.Reduce(999) // Non-existent item
@@ -160,7 +160,7 @@ func TestRotor(t *testing.T) {
 			3: 30,
 			4: 40,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		// Initial state: [4, 3, 2, 1] (sorted by weight)
 
@@ -208,7 +208,7 @@ func TestRotor(t *testing.T) {
 			4: 20,
 			5: 8,
 		}
-		wl := NewRotor(items)
+		wl := NewPriority(items)
 
 		// Should be ordered: [4, 2, 3, 5, 1]
 		expectedOrder := []uint{4, 2, 3, 5, 1}
@@ -257,7 +257,7 @@ func BenchmarkLottery(b *testing.B) {
 	})
 }
 
-func BenchmarkRotor(b *testing.B) {
+func BenchmarkPriority(b *testing.B) {
 	items := map[uint]int{
 		1: 10,
 		2: 20,
@@ -268,22 +268,47 @@ func BenchmarkRotor(b *testing.B) {
 
 	b.Run("Pop", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			wl := NewRotor(items)
+			wl := NewPriority(items)
 			wl.Pop()
 		}
 	})
 
 	b.Run("Delete", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			wl := NewRotor(items)
+			wl := NewPriority(items)
 			wl.Delete(3)
 		}
 	})
 
 	b.Run("Reduce", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			wl := NewRotor(items)
+			wl := NewPriority(items)
 			wl.Reduce(3)
 		}
 	})
 }
+
+func TestCheapest(t *testing.T) {
+	items := map[uint]int{1: 1, 2: 1, 3: 5, 4: 1, 5: 10}
+	prices := map[uint]Price{
+		1: {Input: 3, Output: 15},
+		2: {Input: 1, Output: 5, Cached: 0.5},
+		3: {Input: 1, Output: 5, Cached: 0.5}, // 与 2 同价，权重更高优先
+		4: {Input: 1, Output: 5, Cached: 0.1},
+		// 5 未配置价格，排在最后
+	}
+	wl := NewCheapest(items, prices)
+
+	var order []uint
+	for wl.Len() > 0 {
+		id, _ := wl.Pop()
+		order = append(order, id)
+		wl.Delete(id)
+	}
+	want := []uint{4, 3, 2, 1, 5}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
@@ -13,17 +13,56 @@ const (
 	StateHalfOpen              // 探测恢复
 )
 
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
 type Node struct {
 	state        State     // 熔断状态
 	failCount    int       // 失败次数
 	successCount int       // 成功次数
 	expiry       time.Time // 冷却结束时间
+	forced       bool      // 手动熔断，不会自动进入 HalfOpen
+	window       []result  // 失败率统计窗口内的调用结果
+}
+
+type result struct {
+	at     time.Time
+	failed bool
 }
 
 func (n *Node) Reset(state State) {
 	n.state = state
+	n.forced = false
 	n.failCount = 0
 	n.successCount = 0
+	n.window = nil
+}
+
+// record 记录一次调用结果并返回窗口内的请求数与失败率
+func (n *Node) record(failed bool, window time.Duration) (int, float64) {
+	now := time.Now()
+	n.window = append(n.window, result{at: now, failed: failed})
+	i := 0
+	for i < len(n.window) && now.Sub(n.window[i].at) > window {
+		i++
+	}
+	n.window = n.window[i:]
+
+	failures := 0
+	for _, r := range n.window {
+		if r.failed {
+			failures++
+		}
+	}
+	return len(n.window), float64(failures) / float64(len(n.window))
 }
 
 var (
@@ -32,24 +71,173 @@ var (
 	MaxFailures = 5                // 最多失败次数
 	SleepWindow = 60 * time.Second // 冷却时间
 	MaxRequests = 2                // 在 HalfOpen 状态下, 如果请求成功次数超过此数值，熔断器关闭（恢复）；如果有一个失败，重新进入 Open 状态
+	MinRequests = 10               // 失败率熔断生效所需的最少请求数，避免少量请求时单次失败即熔断
 )
 
+// BreakerConfig 模型级熔断参数，零值项使用包级默认值
+type BreakerConfig struct {
+	MaxFailures int
+	SleepWindow time.Duration
+	MaxRequests int
+	FailureRate float64       // 窗口内失败率达到该值时熔断，0 表示不按失败率熔断
+	MinRequests int           // 窗口内请求数达到该值后失败率才生效
+	Window      time.Duration // 失败率统计窗口，默认与 SleepWindow 相同
+}
+
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	if c.MaxFailures <= 0 {
+		c.MaxFailures = MaxFailures
+	}
+	if c.SleepWindow <= 0 {
+		c.SleepWindow = SleepWindow
+	}
+	if c.MaxRequests <= 0 {
+		c.MaxRequests = MaxRequests
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = MinRequests
+	}
+	// 未配置统计窗口时与模型的冷却时间一致
+	if c.Window <= 0 {
+		c.Window = c.SleepWindow
+	}
+	return c
+}
+
+// Transition 熔断状态变更
+type Transition struct {
+	Key       uint
+	From      State
+	To        State
+	FailCount int
+	Expiry    time.Time
+	Forced    bool
+	Reason    string
+}
+
+// OnTransition 状态变更回调，在锁外同步调用，耗时操作需自行异步处理
+var OnTransition func(Transition)
+
+func emit(transitions []Transition) {
+	if OnTransition == nil {
+		return
+	}
+	for _, t := range transitions {
+		OnTransition(t)
+	}
+}
+
+// transit 切换节点状态并返回变更记录，调用方需持有 mu
+func transit(key uint, node *Node, state State, expiry time.Time, reason string) Transition {
+	t := Transition{Key: key, From: node.state, To: state, FailCount: node.failCount, Reason: reason}
+	node.Reset(state)
+	node.expiry = expiry
+	t.Expiry = expiry
+	return t
+}
+
+// RestoreNode 恢复持久化的节点状态，已过冷却期的 Open 节点在下次请求时进入 HalfOpen
+func RestoreNode(key uint, state State, expiry time.Time, forced bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	nodes[key] = &Node{state: state, expiry: expiry, forced: forced && state == StateOpen}
+}
+
+// DeleteNode 删除关联的熔断状态，关联被删除时调用
+func DeleteNode(key uint) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(nodes, key)
+}
+
+// NodeStat 节点状态快照
+type NodeStat struct {
+	State     State
+	FailCount int
+	Expiry    time.Time
+	Forced    bool
+}
+
+// NodeStats 返回全部节点的状态快照，未出现的关联视为 Closed
+func NodeStats() map[uint]NodeStat {
+	mu.Lock()
+	defer mu.Unlock()
+	stats := make(map[uint]NodeStat, len(nodes))
+	for key, node := range nodes {
+		stats[key] = NodeStat{State: node.state, FailCount: node.failCount, Expiry: node.expiry, Forced: node.forced}
+	}
+	return stats
+}
+
+// ForceOpen 手动熔断关联，直到 ForceClose 前都不会被选择，未开启熔断的模型同样生效
+func ForceOpen(key uint) {
+	var transitions []Transition
+	defer func() { emit(transitions) }()
+
+	mu.Lock()
+	defer mu.Unlock()
+	node, ok := nodes[key]
+	if !ok {
+		node = &Node{state: StateClosed}
+		nodes[key] = node
+	}
+	t := transit(key, node, StateOpen, time.Time{}, "forced open")
+	node.forced = true
+	t.Forced = true
+	transitions = append(transitions, t)
+}
+
+// ForceClose 手动恢复关联，清空失败次数
+func ForceClose(key uint) {
+	var transitions []Transition
+	defer func() { emit(transitions) }()
+
+	mu.Lock()
+	defer mu.Unlock()
+	node, ok := nodes[key]
+	if !ok {
+		node = &Node{state: StateClosed}
+		nodes[key] = node
+	}
+	transitions = append(transitions, transit(key, node, StateClosed, time.Time{}, "forced close"))
+}
+
+// ExcludeForced 未开启熔断的模型仅排除手动熔断的关联
+func ExcludeForced(balancer Balancer) Balancer {
+	mu.Lock()
+	defer mu.Unlock()
+	for key, node := range nodes {
+		if node.forced {
+			balancer.Delete(key)
+		}
+	}
+	return balancer
+}
+
 type Breaker struct {
 	Balancer
+	config BreakerConfig
 }
 
 func BalancerWrapperBreaker(balancer Balancer) *Breaker {
+	return BalancerWrapperBreakerWithConfig(balancer, BreakerConfig{})
+}
+
+func BalancerWrapperBreakerWithConfig(balancer Balancer, config BreakerConfig) *Breaker {
+	var transitions []Transition
+	defer func() { emit(transitions) }()
+
 	mu.Lock()
 	defer mu.Unlock()
 	for key, node := range nodes {
-		if node.state == StateOpen && node.expiry.Before(time.Now()) {
-			node.Reset(StateHalfOpen)
+		if node.state == StateOpen && !node.forced && node.expiry.Before(time.Now()) {
+			transitions = append(transitions, transit(key, node, StateHalfOpen, node.expiry, "sleep window elapsed"))
 		}
 		if node.state == StateOpen {
 			balancer.Delete(key)
 		}
 	}
-	return &Breaker{Balancer: balancer}
+	return &Breaker{Balancer: balancer, config: config.withDefaults()}
 }
 
 func (b *Breaker) Pop() (uint, error) {
@@ -76,32 +264,46 @@ func (b *Breaker) Reduce(key uint) {
 }
 
 func (b *Breaker) failCountAdd(key uint) {
+	var transitions []Transition
+	defer func() { emit(transitions) }()
+
 	mu.Lock()
 	defer mu.Unlock()
 	if node, ok := nodes[key]; ok {
 		node.failCount += 1
-		if node.state == StateClosed && node.failCount >= MaxFailures {
-			node.Reset(StateOpen)
-			node.expiry = time.Now().Add(SleepWindow)
+		expiry := time.Now().Add(b.config.SleepWindow)
+		if node.state == StateClosed {
+			if node.failCount >= b.config.MaxFailures {
+				transitions = append(transitions, transit(key, node, StateOpen, expiry, "max failures reached"))
+			} else if b.config.FailureRate > 0 {
+				if requests, rate := node.record(true, b.config.Window); requests >= b.config.MinRequests && rate >= b.config.FailureRate {
+					transitions = append(transitions, transit(key, node, StateOpen, expiry, "failure rate reached"))
+				}
+			}
 		}
 
 		if node.state == StateHalfOpen {
-			node.Reset(StateOpen)
-			node.expiry = time.Now().Add(SleepWindow)
+			transitions = append(transitions, transit(key, node, StateOpen, expiry, "half-open probe failed"))
 		}
 	}
 }
 
 func (b *Breaker) Success(key uint) {
+	var transitions []Transition
+	defer func() { emit(transitions) }()
+
 	mu.Lock()
-	defer mu.Unlock()
 	if node, ok := nodes[key]; ok {
+		if node.state == StateClosed && b.config.FailureRate > 0 {
+			node.record(false, b.config.Window)
+		}
 		if node.state == StateHalfOpen {
 			node.successCount += 1
-			if node.successCount >= MaxRequests {
-				node.Reset(StateClosed)
+			if node.successCount >= b.config.MaxRequests {
+				transitions = append(transitions, transit(key, node, StateClosed, time.Time{}, "half-open probes succeeded"))
 			}
 		}
 	}
+	mu.Unlock()
 	b.Balancer.Success(key)
 }
@@ -217,3 +217,127 @@ func TestBreakerHalfOpenDeleteReopens(t *testing.T) {
 		t.Fatalf("underlying Delete calls = %v, want [7]", spy.deletes)
 	}
 }
+
+func TestBreakerConfigPerWrapper(t *testing.T) {
+	resetBreakerState(t)
+	withBreakerConfig(t, 5, time.Minute, 2)
+
+	spy := &spyBalancer{nextKey: 9}
+	breaker := BalancerWrapperBreakerWithConfig(spy, BreakerConfig{MaxFailures: 1, SleepWindow: time.Hour})
+	breaker.Pop()
+	breaker.Delete(9)
+
+	mu.Lock()
+	node := nodes[9]
+	mu.Unlock()
+	if node.state != StateOpen {
+		t.Fatalf("state = %v, want %v after 1 failure", node.state, StateOpen)
+	}
+	if node.expiry.Before(time.Now().Add(30 * time.Minute)) {
+		t.Fatalf("expiry = %v, want model sleep window", node.expiry)
+	}
+}
+
+func TestBreakerFailureRate(t *testing.T) {
+	resetBreakerState(t)
+	withBreakerConfig(t, 100, time.Minute, 2)
+
+	var transitions []Transition
+	OnTransition = func(t Transition) { transitions = append(transitions, t) }
+	t.Cleanup(func() { OnTransition = nil })
+
+	spy := &spyBalancer{nextKey: 11}
+	breaker := BalancerWrapperBreakerWithConfig(spy, BreakerConfig{FailureRate: 0.5, MinRequests: 4, Window: time.Minute})
+	breaker.Pop()
+	breaker.Success(11)
+	breaker.Delete(11)
+	breaker.Success(11)
+	if len(transitions) != 0 {
+		t.Fatalf("tripped before min requests: %+v", transitions)
+	}
+	breaker.Delete(11)
+
+	if len(transitions) != 1 || transitions[0].From != StateClosed || transitions[0].To != StateOpen || transitions[0].Key != 11 {
+		t.Fatalf("transitions = %+v, want closed -> open", transitions)
+	}
+}
+
+func TestBreakerFailureRateDefaultMinRequests(t *testing.T) {
+	resetBreakerState(t)
+	withBreakerConfig(t, 100, time.Minute, 2)
+
+	var transitions []Transition
+	OnTransition = func(t Transition) { transitions = append(transitions, t) }
+	t.Cleanup(func() { OnTransition = nil })
+
+	// 未配置 MinRequests 时使用默认值，首次失败不应熔断
+	spy := &spyBalancer{nextKey: 12}
+	breaker := BalancerWrapperBreakerWithConfig(spy, BreakerConfig{FailureRate: 0.5, Window: time.Minute})
+	breaker.Pop()
+	for range MinRequests - 1 {
+		breaker.Delete(12)
+	}
+	if len(transitions) != 0 {
+		t.Fatalf("tripped before default min requests: %+v", transitions)
+	}
+	breaker.Delete(12)
+	if len(transitions) != 1 || transitions[0].To != StateOpen || transitions[0].Reason != "failure rate reached" {
+		t.Fatalf("transitions = %+v, want failure rate trip", transitions)
+	}
+}
+
+func TestBreakerConfigWindowDefaultsToSleepWindow(t *testing.T) {
+	if got := (BreakerConfig{SleepWindow: 10 * time.Second}).withDefaults().Window; got != 10*time.Second {
+		t.Errorf("Window = %v, want model sleep window 10s", got)
+	}
+	if got := (BreakerConfig{}).withDefaults().Window; got != SleepWindow {
+		t.Errorf("Window = %v, want default sleep window %v", got, SleepWindow)
+	}
+	if got := (BreakerConfig{SleepWindow: 10 * time.Second, Window: time.Minute}).withDefaults().Window; got != time.Minute {
+		t.Errorf("Window = %v, want configured 1m", got)
+	}
+}
+
+func TestRestoreNode(t *testing.T) {
+	resetBreakerState(t)
+
+	RestoreNode(13, StateOpen, time.Now().Add(time.Minute), false)
+	spy := &spyBalancer{nextKey: 13}
+	BalancerWrapperBreaker(spy)
+	if len(spy.deletes) != 1 || spy.deletes[0] != 13 {
+		t.Fatalf("restored open node not excluded, deletes = %v", spy.deletes)
+	}
+}
+
+func TestForceOpenAndClose(t *testing.T) {
+	resetBreakerState(t)
+
+	var transitions []Transition
+	OnTransition = func(t Transition) { transitions = append(transitions, t) }
+	t.Cleanup(func() { OnTransition = nil })
+
+	ForceOpen(21)
+	// 手动熔断不会因冷却期结束进入 HalfOpen
+	spy := &spyBalancer{nextKey: 21}
+	BalancerWrapperBreaker(spy)
+	if len(spy.deletes) != 1 || spy.deletes[0] != 21 {
+		t.Fatalf("forced node not excluded, deletes = %v", spy.deletes)
+	}
+	// 未开启熔断的模型同样排除
+	spy = &spyBalancer{nextKey: 21}
+	ExcludeForced(spy)
+	if len(spy.deletes) != 1 {
+		t.Fatalf("ExcludeForced deletes = %v, want [21]", spy.deletes)
+	}
+	if stat := NodeStats()[21]; stat.State != StateOpen || !stat.Forced {
+		t.Fatalf("stat = %+v, want forced open", stat)
+	}
+
+	ForceClose(21)
+	if stat := NodeStats()[21]; stat.State != StateClosed || stat.Forced {
+		t.Fatalf("stat = %+v, want closed", stat)
+	}
+	if len(transitions) != 2 || !transitions[0].Forced || transitions[1].From != StateOpen || transitions[1].To != StateClosed {
+		t.Fatalf("transitions = %+v", transitions)
+	}
+}
@@ -0,0 +1,42 @@
+package balancers
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+var EndpointStickiness = 5 * time.Minute // 故障切换后记住可用端点的时长
+
+type endpointState struct {
+	baseURL string
+	expiry  time.Time
+}
+
+var (
+	endpointsMu sync.Mutex
+	endpoints   = make(map[uint]endpointState)
+)
+
+// EndpointOrder 返回提供商各 base URL 的尝试顺序，记住的可用端点优先，其余保持配置顺序
+func EndpointOrder(providerID uint, baseURLs []string) []string {
+	endpointsMu.Lock()
+	state, ok := endpoints[providerID]
+	endpointsMu.Unlock()
+
+	ordered := slices.Clone(baseURLs)
+	if !ok || state.expiry.Before(time.Now()) {
+		return ordered
+	}
+	if i := slices.Index(ordered, state.baseURL); i > 0 {
+		ordered = slices.Insert(slices.Delete(ordered, i, i+1), 0, state.baseURL)
+	}
+	return ordered
+}
+
+// EndpointHealthy 故障切换成功后记录可用端点，到期后重新按配置顺序尝试
+func EndpointHealthy(providerID uint, baseURL string) {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	endpoints[providerID] = endpointState{baseURL: baseURL, expiry: time.Now().Add(EndpointStickiness)}
+}
@@ -0,0 +1,222 @@
+package balancers
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/samber/lo"
+)
+
+var (
+	RateLimitQuarantine = 60 * time.Second // 429 且未返回 Retry-After 时的隔离时间
+	AuthQuarantine      = 10 * time.Minute // 401/403 的隔离时间
+)
+
+// APIKey 提供商配置中 api_keys 的一项
+type APIKey struct {
+	Key    string `json:"key"`
+	Weight int    `json:"weight"`
+}
+
+// KeyStat 单个 Key 的运行状态，Key 已脱敏
+type KeyStat struct {
+	Key               string     `json:"key"`
+	Weight            int        `json:"weight"`
+	Successes         int64      `json:"successes"`
+	Failures          int64      `json:"failures"`
+	LastStatus        int        `json:"last_status"`
+	LastRateLimitedAt *time.Time `json:"last_rate_limited_at"`
+	QuarantinedUntil  *time.Time `json:"quarantined_until"`
+}
+
+type keyState struct {
+	APIKey
+	current           int // 平滑加权轮询的当前权重
+	lastUsed          time.Time
+	successes         int64
+	failures          int64
+	lastStatus        int
+	lastRateLimitedAt time.Time
+	quarantinedUntil  time.Time
+}
+
+// KeyPool 单个提供商的 API Key 池，状态在进程内共享
+type KeyPool struct {
+	mu       sync.Mutex
+	strategy string
+	keys     []*keyState
+}
+
+var (
+	keyPoolsMu sync.Mutex
+	keyPools   = make(map[uint]*KeyPool)
+)
+
+// GetKeyPool 返回提供商的 Key 池，配置变化时保留仍存在的 Key 的状态
+func GetKeyPool(providerID uint, strategy string, keys []APIKey) *KeyPool {
+	keyPoolsMu.Lock()
+	pool, ok := keyPools[providerID]
+	if !ok {
+		pool = &KeyPool{}
+		keyPools[providerID] = pool
+	}
+	keyPoolsMu.Unlock()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.strategy = strategy
+	existing := make(map[string]*keyState, len(pool.keys))
+	for _, state := range pool.keys {
+		existing[state.Key] = state
+	}
+	states := make([]*keyState, 0, len(keys))
+	for _, key := range keys {
+		if key.Weight <= 0 {
+			key.Weight = 1
+		}
+		state, ok := existing[key.Key]
+		if !ok {
+			state = &keyState{}
+		}
+		state.APIKey = key
+		states = append(states, state)
+	}
+	pool.keys = states
+	return pool
+}
+
+// Pick 从未被隔离的 Key 中选择一个
+func (p *KeyPool) Pick() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+
+	var picked *keyState
+	switch p.strategy {
+	case consts.KeyStrategyLeastRateLimited:
+		// 最久未限流优先，相同时使用最久未使用的
+		for _, state := range p.keys {
+			if state.quarantinedUntil.After(now) {
+				continue
+			}
+			if picked == nil || state.lastRateLimitedAt.Before(picked.lastRateLimitedAt) ||
+				state.lastRateLimitedAt.Equal(picked.lastRateLimitedAt) && state.lastUsed.Before(picked.lastUsed) {
+				picked = state
+			}
+		}
+	default:
+		// 平滑加权轮询
+		total := 0
+		for _, state := range p.keys {
+			if state.quarantinedUntil.After(now) {
+				continue
+			}
+			state.current += state.Weight
+			total += state.Weight
+			if picked == nil || state.current > picked.current {
+				picked = state
+			}
+		}
+		if picked != nil {
+			picked.current -= total
+		}
+	}
+	if picked == nil {
+		return "", errors.New("all api keys are quarantined")
+	}
+	picked.lastUsed = now
+	return picked.Key, nil
+}
+
+// Available 返回当前未被隔离的 Key 数量
+func (p *KeyPool) Available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var count int
+	for _, state := range p.keys {
+		if !state.quarantinedUntil.After(now) {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *KeyPool) find(key string) *keyState {
+	for _, state := range p.keys {
+		if state.Key == key {
+			return state
+		}
+	}
+	return nil
+}
+
+func (p *KeyPool) Success(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if state := p.find(key); state != nil {
+		state.successes++
+		state.lastStatus = http.StatusOK
+	}
+}
+
+// Failure 记录失败，401/403/429 时隔离该 Key，返回是否已隔离
+func (p *KeyPool) Failure(key string, status int, retryAfter time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.find(key)
+	if state == nil {
+		return false
+	}
+	state.failures++
+	state.lastStatus = status
+	now := time.Now()
+	switch status {
+	case http.StatusTooManyRequests:
+		state.lastRateLimitedAt = now
+		if retryAfter <= 0 {
+			retryAfter = RateLimitQuarantine
+		}
+		state.quarantinedUntil = now.Add(retryAfter)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		state.quarantinedUntil = now.Add(AuthQuarantine)
+	default:
+		return false
+	}
+	return true
+}
+
+func (p *KeyPool) Stats() []KeyStat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	stats := make([]KeyStat, 0, len(p.keys))
+	for _, state := range p.keys {
+		stat := KeyStat{
+			Key:        MaskKey(state.Key),
+			Weight:     state.Weight,
+			Successes:  state.successes,
+			Failures:   state.failures,
+			LastStatus: state.lastStatus,
+		}
+		if !state.lastRateLimitedAt.IsZero() {
+			stat.LastRateLimitedAt = lo.ToPtr(state.lastRateLimitedAt)
+		}
+		if state.quarantinedUntil.After(now) {
+			stat.QuarantinedUntil = lo.ToPtr(state.quarantinedUntil)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// MaskKey 仅保留首尾各 4 个字符
+func MaskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****" + key[len(key)-4:]
+}
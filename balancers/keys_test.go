@@ -0,0 +1,97 @@
+package balancers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/atopos31/llmio/consts"
+)
+
+func TestKeyPoolRoundRobinWeights(t *testing.T) {
+	pool := GetKeyPool(1001, consts.KeyStrategyRoundRobin, []APIKey{{Key: "sk-a", Weight: 2}, {Key: "sk-b", Weight: 1}})
+	counts := map[string]int{}
+	var order string
+	for range 6 {
+		key, err := pool.Pick()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[key]++
+		order += key[3:]
+	}
+	if counts["sk-a"] != 4 || counts["sk-b"] != 2 {
+		t.Fatalf("unexpected distribution: %v", counts)
+	}
+	// 平滑轮询不会连续选择同一个 Key 三次
+	if order != "abaaba" {
+		t.Fatalf("order = %q", order)
+	}
+}
+
+func TestKeyPoolQuarantine(t *testing.T) {
+	pool := GetKeyPool(1002, consts.KeyStrategyRoundRobin, []APIKey{{Key: "sk-a"}, {Key: "sk-b"}})
+	if !pool.Failure("sk-a", http.StatusTooManyRequests, 0) {
+		t.Fatal("429 should quarantine the key")
+	}
+	if pool.Failure("sk-b", http.StatusInternalServerError, 0) {
+		t.Fatal("500 should not quarantine the key")
+	}
+	for range 3 {
+		if key, _ := pool.Pick(); key != "sk-b" {
+			t.Fatalf("picked quarantined key %q", key)
+		}
+	}
+	pool.Failure("sk-b", http.StatusUnauthorized, 0)
+	if pool.Available() != 0 {
+		t.Fatalf("available = %d, want 0", pool.Available())
+	}
+	if _, err := pool.Pick(); err == nil {
+		t.Fatal("expected error when all keys are quarantined")
+	}
+
+	// 隔离到期后恢复
+	pool.keys[0].quarantinedUntil = time.Now().Add(-time.Second)
+	if key, err := pool.Pick(); err != nil || key != "sk-a" {
+		t.Fatalf("Pick() = %q, %v", key, err)
+	}
+}
+
+func TestKeyPoolLeastRateLimited(t *testing.T) {
+	pool := GetKeyPool(1003, consts.KeyStrategyLeastRateLimited, []APIKey{{Key: "sk-a"}, {Key: "sk-b"}, {Key: "sk-c"}})
+	pool.Failure("sk-a", http.StatusTooManyRequests, time.Millisecond)
+	pool.Failure("sk-b", http.StatusTooManyRequests, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	// 从未限流的 Key 优先
+	if key, _ := pool.Pick(); key != "sk-c" {
+		t.Fatalf("Pick() = %q, want sk-c", key)
+	}
+	// 其次为最早被限流的
+	pool.Failure("sk-c", http.StatusTooManyRequests, time.Hour)
+	for range 2 {
+		if key, _ := pool.Pick(); key != "sk-a" {
+			t.Fatalf("Pick() = %q, want sk-a", key)
+		}
+	}
+}
+
+func TestKeyPoolStats(t *testing.T) {
+	keys := []APIKey{{Key: "sk-aaaaaaaa1111", Weight: 3}}
+	pool := GetKeyPool(1004, "", keys)
+	pool.Success("sk-aaaaaaaa1111")
+	pool.Success("sk-aaaaaaaa1111")
+	pool.Failure("sk-aaaaaaaa1111", http.StatusBadGateway, 0)
+
+	// 配置更新后保留已有 Key 的统计
+	stats := GetKeyPool(1004, "", append(keys, APIKey{Key: "sk-bbbbbbbb2222"})).Stats()
+	if len(stats) != 2 {
+		t.Fatalf("got %d stats, want 2", len(stats))
+	}
+	if stats[0].Key != "sk-a****1111" || stats[0].Successes != 2 || stats[0].Failures != 1 || stats[0].LastStatus != http.StatusBadGateway {
+		t.Fatalf("unexpected stat: %+v", stats[0])
+	}
+	if stats[1].Weight != 1 || stats[1].QuarantinedUntil != nil {
+		t.Fatalf("unexpected stat: %+v", stats[1])
+	}
+}
@@ -0,0 +1,93 @@
+package balancers
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+var (
+	inflightMu sync.Mutex
+	inflight   = make(map[uint]int) // 各关联正在处理的请求数，进程内共享
+)
+
+// Acquire 记录关联开始处理一个请求，返回的 release 在请求结束时调用，重复调用只生效一次
+func Acquire(key uint) (release func()) {
+	inflightMu.Lock()
+	inflight[key]++
+	inflightMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			inflightMu.Lock()
+			defer inflightMu.Unlock()
+			if inflight[key]--; inflight[key] <= 0 {
+				delete(inflight, key)
+			}
+		})
+	}
+}
+
+// InFlight 返回关联正在处理的请求数
+func InFlight(key uint) int {
+	inflightMu.Lock()
+	defer inflightMu.Unlock()
+	return inflight[key]
+}
+
+// 选择 在途请求数/权重 最小的关联，负载相同时按权重随机选择
+type LeastConn struct {
+	store map[uint]int
+}
+
+func NewLeastConn(items map[uint]int) *LeastConn {
+	return &LeastConn{store: items}
+}
+
+func (w *LeastConn) Pop() (uint, error) {
+	inflightMu.Lock()
+	defer inflightMu.Unlock()
+
+	var (
+		best     []uint
+		total    int
+		bestLoad int
+		bestW    int
+	)
+	for key, weight := range w.store {
+		if weight <= 0 {
+			continue
+		}
+		load := inflight[key]
+		// load/weight 与 bestLoad/bestW 交叉相乘比较，避免浮点误差
+		switch {
+		case len(best) == 0 || load*bestW < bestLoad*weight:
+			best, total, bestLoad, bestW = []uint{key}, weight, load, weight
+		case load*bestW == bestLoad*weight:
+			best = append(best, key)
+			total += weight
+		}
+	}
+	if len(best) == 0 {
+		return 0, fmt.Errorf("no provide items or all items are disabled")
+	}
+	r := rand.IntN(total)
+	for _, key := range best {
+		if r < w.store[key] {
+			return key, nil
+		}
+		r -= w.store[key]
+	}
+	return 0, fmt.Errorf("unexpected error")
+}
+
+func (w *LeastConn) Delete(key uint) {
+	delete(w.store, key)
+}
+
+func (w *LeastConn) Reduce(key uint) {
+	w.store[key] -= w.store[key] / 3
+}
+
+func (w *LeastConn) Success(key uint) {}
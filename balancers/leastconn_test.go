@@ -0,0 +1,41 @@
+package balancers
+
+import "testing"
+
+func TestLeastConnPrefersLowerLoadPerWeight(t *testing.T) {
+	releases := []func(){Acquire(3001), Acquire(3001), Acquire(3002)}
+	defer func() {
+		for _, release := range releases {
+			release()
+		}
+	}()
+
+	// 3001: 2/4，3002: 1/1，3003: 0/1 空闲优先
+	w := NewLeastConn(map[uint]int{3001: 4, 3002: 1, 3003: 1})
+	if id, err := w.Pop(); err != nil || id != 3003 {
+		t.Fatalf("got %d, %v, want 3003", id, err)
+	}
+	w.Delete(3003)
+	if id, _ := w.Pop(); id != 3001 {
+		t.Fatalf("got %d, want 3001", id)
+	}
+}
+
+func TestLeastConnRelease(t *testing.T) {
+	release := Acquire(3004)
+	if InFlight(3004) != 1 {
+		t.Fatalf("in flight = %d, want 1", InFlight(3004))
+	}
+	release()
+	release()
+	if InFlight(3004) != 0 {
+		t.Fatalf("in flight = %d after release, want 0", InFlight(3004))
+	}
+}
+
+func TestLeastConnEmpty(t *testing.T) {
+	w := NewLeastConn(map[uint]int{1: 0})
+	if _, err := w.Pop(); err == nil {
+		t.Fatal("expected error when all weights are zero")
+	}
+}
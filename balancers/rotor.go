@@ -0,0 +1,96 @@
+package balancers
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// rotorKey 轮询状态按模型与优先级分组保存
+type rotorKey struct {
+	model uint
+	tier  int
+}
+
+var (
+	rotorMu sync.Mutex
+	rotors  = make(map[rotorKey]map[uint]int) // 各分组下关联的平滑轮询当前权重，跨请求保留
+)
+
+// 平滑加权轮询(nginx smooth WRR)，轮询状态按模型跨请求保留
+type Rotor struct {
+	current map[uint]int
+	store   map[uint]int
+}
+
+// NewRotor 返回模型在优先级分组 tier 下的轮询器。
+// items 是本次请求过滤后的候选，未出现的关联保留其轮询状态
+func NewRotor(modelID uint, tier int, items map[uint]int) *Rotor {
+	rotorMu.Lock()
+	defer rotorMu.Unlock()
+	key := rotorKey{model: modelID, tier: tier}
+	current, ok := rotors[key]
+	if !ok {
+		current = make(map[uint]int)
+		rotors[key] = current
+	}
+	return &Rotor{current: current, store: items}
+}
+
+// ResetRotor 清除关联的轮询状态，关联被删除或修改权重、分组时调用
+func ResetRotor(modelWithProviderID uint) {
+	rotorMu.Lock()
+	defer rotorMu.Unlock()
+	for _, current := range rotors {
+		delete(current, modelWithProviderID)
+	}
+}
+
+// DeleteRotors 删除模型的全部轮询状态
+func DeleteRotors(modelID uint) {
+	rotorMu.Lock()
+	defer rotorMu.Unlock()
+	maps.DeleteFunc(rotors, func(key rotorKey, _ map[uint]int) bool {
+		return key.model == modelID
+	})
+}
+
+// Pop 每个候选当前权重加上自身权重，选出最大者后减去候选权重总和
+func (w *Rotor) Pop() (uint, error) {
+	rotorMu.Lock()
+	defer rotorMu.Unlock()
+
+	var (
+		best  uint
+		found bool
+		total int
+	)
+	// 只遍历本次请求的候选，按 key 顺序遍历，当前权重相同时结果稳定
+	for _, key := range slices.Sorted(maps.Keys(w.store)) {
+		weight := w.store[key]
+		if weight <= 0 {
+			continue
+		}
+		w.current[key] += weight
+		total += weight
+		if !found || w.current[key] > w.current[best] {
+			best, found = key, true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no provide items or all items are disabled")
+	}
+	w.current[best] -= total
+	return best, nil
+}
+
+func (w *Rotor) Delete(key uint) {
+	delete(w.store, key)
+}
+
+func (w *Rotor) Reduce(key uint) {
+	w.store[key] -= w.store[key] / 3
+}
+
+func (w *Rotor) Success(key uint) {}
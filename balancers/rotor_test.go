@@ -0,0 +1,64 @@
+package balancers
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestRotorSmoothAcrossRequests(t *testing.T) {
+	items := map[uint]int{1: 5, 2: 1, 3: 1}
+	var order []uint
+	// 每次请求新建 Rotor，轮询位置仍然延续
+	for range 7 {
+		id, err := NewRotor(4001, 0, maps.Clone(items)).Pop()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		order = append(order, id)
+	}
+	want := []uint{1, 1, 2, 1, 3, 1, 1}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRotorDelete(t *testing.T) {
+	w := NewRotor(4002, 0, map[uint]int{1: 1, 2: 1})
+	first, _ := w.Pop()
+	w.Delete(first)
+	second, err := w.Pop()
+	if err != nil || second == first {
+		t.Fatalf("got %d, %v after deleting %d", second, err, first)
+	}
+	w.Delete(second)
+	if _, err := w.Pop(); err == nil {
+		t.Fatal("expected error after deleting all items")
+	}
+}
+
+func TestRotorKeepsStateOfFilteredKeys(t *testing.T) {
+	NewRotor(4003, 0, map[uint]int{1: 1, 2: 1}).Pop()
+
+	// 本次请求过滤掉关联 2，不应清除其轮询状态
+	NewRotor(4003, 0, map[uint]int{1: 1}).Pop()
+	if _, ok := rotors[rotorKey{model: 4003}][2]; !ok {
+		t.Fatal("state of filtered key 2 should be kept")
+	}
+
+	ResetRotor(2)
+	if _, ok := rotors[rotorKey{model: 4003}][2]; ok {
+		t.Error("state of key 2 should be reset")
+	}
+	if _, ok := rotors[rotorKey{model: 4003}][1]; !ok {
+		t.Error("state of key 1 should be kept")
+	}
+
+	DeleteRotors(4003)
+	for key := range rotors {
+		if key.model == 4003 {
+			t.Fatalf("rotor state %+v should be deleted", key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package balancers
+
+import (
+	"math"
+	"time"
+)
+
+// smartScale 动态权重的放大倍数，避免小权重取整后失去区分度
+const smartScale = 100
+
+// SmartStat 单个关联在统计窗口内按时间衰减累计的调用情况
+type SmartStat struct {
+	Total        float64 // 全部调用
+	Success      float64 // 成功调用
+	Latency      float64 // 成功调用的首字延迟(秒)之和
+	LatencyCount float64 // 计入延迟的成功调用
+}
+
+// Add 按 age 相对 window 的线性衰减累加一次调用，超出窗口的调用忽略
+func (s *SmartStat) Add(age, window time.Duration, success bool, firstChunk time.Duration) {
+	if window <= 0 || age >= window {
+		return
+	}
+	decay := 1 - max(age, 0).Seconds()/window.Seconds()
+	s.Total += decay
+	if !success {
+		return
+	}
+	s.Success += decay
+	if firstChunk > 0 {
+		s.Latency += decay * firstChunk.Seconds()
+		s.LatencyCount += decay
+	}
+}
+
+func (s SmartStat) successRate() float64 {
+	if s.Total <= 0 {
+		return 1
+	}
+	return s.Success / s.Total
+}
+
+func (s SmartStat) avgLatency() float64 {
+	if s.LatencyCount <= 0 {
+		return 0
+	}
+	return s.Latency / s.LatencyCount
+}
+
+// SmartWeights 根据成功率与首字延迟评分调整静态权重，评分为两者的加权平均，
+// 延迟得分为最快关联延迟与自身延迟之比；无统计数据的关联按满分处理，结果不低于 minWeight
+func SmartWeights(weights map[uint]int, stats map[uint]SmartStat, successRateWeight, responseTimeWeight float64, minWeight int) map[uint]int {
+	fastest := math.Inf(1)
+	for key := range weights {
+		if latency := stats[key].avgLatency(); latency > 0 {
+			fastest = min(fastest, latency)
+		}
+	}
+
+	result := make(map[uint]int, len(weights))
+	for key, weight := range weights {
+		if weight <= 0 {
+			result[key] = weight
+			continue
+		}
+		stat := stats[key]
+		latencyScore := 1.0
+		if latency := stat.avgLatency(); latency > 0 {
+			latencyScore = fastest / latency
+		}
+		score := 1.0
+		if total := successRateWeight + responseTimeWeight; total > 0 {
+			score = (successRateWeight*stat.successRate() + responseTimeWeight*latencyScore) / total
+		}
+		result[key] = max(minWeight*smartScale, int(math.Round(float64(weight*smartScale)*score)))
+	}
+	return result
+}
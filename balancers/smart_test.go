@@ -0,0 +1,42 @@
+package balancers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSmartStatDecay(t *testing.T) {
+	var stat SmartStat
+	stat.Add(0, time.Hour, true, time.Second)
+	stat.Add(30*time.Minute, time.Hour, false, 0)
+	stat.Add(2*time.Hour, time.Hour, false, 0) // 超出窗口
+	if stat.Total != 1.5 || stat.Success != 1 {
+		t.Fatalf("stat = %+v", stat)
+	}
+	if rate := stat.successRate(); rate < 0.66 || rate > 0.67 {
+		t.Fatalf("success rate = %f", rate)
+	}
+}
+
+func TestSmartWeights(t *testing.T) {
+	weights := map[uint]int{1: 4, 2: 4, 3: 4, 4: 0}
+	stats := map[uint]SmartStat{
+		1: {Total: 10, Success: 10, Latency: 10, LatencyCount: 10}, // 全部成功 1s
+		2: {Total: 10, Success: 5, Latency: 10, LatencyCount: 5},   // 一半成功 2s
+		// 3 无数据按满分处理
+	}
+
+	got := SmartWeights(weights, stats, 0.5, 0.5, 1)
+	want := map[uint]int{1: 400, 2: 200, 3: 400, 4: 0}
+	for key, w := range want {
+		if got[key] != w {
+			t.Fatalf("weights = %v, want %v", got, want)
+		}
+	}
+
+	// 不低于下限
+	stats[2] = SmartStat{Total: 10}
+	if got := SmartWeights(weights, stats, 1, 0, 1); got[2] != 100 {
+		t.Fatalf("weight = %d, want min weight", got[2])
+	}
+}
@@ -0,0 +1,67 @@
+package balancers
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+)
+
+// 按会话标识一致性哈希(加权 rendezvous hashing)选择固定关联，提高上游提示词缓存命中率；
+// 目标不可用或无会话标识时使用 fallback
+type Sticky struct {
+	target   uint
+	sticky   bool
+	fallback Balancer
+}
+
+func NewSticky(session string, items map[uint]int, fallback Balancer) *Sticky {
+	s := &Sticky{fallback: fallback}
+	if session == "" {
+		return s
+	}
+	best := math.Inf(-1)
+	for key, weight := range items {
+		if weight <= 0 {
+			continue
+		}
+		if score := rendezvousScore(session, key, weight); score > best {
+			s.target, s.sticky, best = key, true, score
+		}
+	}
+	return s
+}
+
+// rendezvousScore 加权 rendezvous 得分 w / -ln(u)，增删关联只影响映射到该关联的会话
+func rendezvousScore(session string, key uint, weight int) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(session))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatUint(uint64(key), 10)))
+	u := (float64(h.Sum64()>>11) + 0.5) / (1 << 53)
+	return float64(weight) / -math.Log(u)
+}
+
+func (s *Sticky) Pop() (uint, error) {
+	if s.sticky {
+		return s.target, nil
+	}
+	return s.fallback.Pop()
+}
+
+func (s *Sticky) Delete(key uint) {
+	if key == s.target {
+		s.sticky = false
+	}
+	s.fallback.Delete(key)
+}
+
+func (s *Sticky) Reduce(key uint) {
+	if key == s.target {
+		s.sticky = false
+	}
+	s.fallback.Reduce(key)
+}
+
+func (s *Sticky) Success(key uint) {
+	s.fallback.Success(key)
+}
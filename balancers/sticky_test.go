@@ -0,0 +1,50 @@
+package balancers
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestStickyConsistent(t *testing.T) {
+	items := map[uint]int{1: 1, 2: 1, 3: 1}
+	newSticky := func(session string, items map[uint]int) *Sticky {
+		return NewSticky(session, items, NewLottery(maps.Clone(items)))
+	}
+
+	moved := 0
+	for i := range 100 {
+		session := string(rune('a'+i%26)) + string(rune('a'+i/26))
+		first, _ := newSticky(session, items).Pop()
+		again, _ := newSticky(session, items).Pop()
+		if first != again {
+			t.Fatalf("session %q mapped to %d then %d", session, first, again)
+		}
+		// 移除关联 3 只影响原本映射到 3 的会话
+		after, _ := newSticky(session, map[uint]int{1: 1, 2: 1}).Pop()
+		if first != 3 && after != first {
+			t.Fatalf("session %q moved from %d to %d", session, first, after)
+		}
+		if first == 3 {
+			moved++
+		}
+	}
+	if moved == 0 || moved == 100 {
+		t.Fatalf("unexpected distribution, %d sessions on key 3", moved)
+	}
+}
+
+func TestStickyFallback(t *testing.T) {
+	items := map[uint]int{1: 1, 2: 1}
+	s := NewSticky("session", items, NewLottery(maps.Clone(items)))
+	target, _ := s.Pop()
+	s.Delete(target)
+	if id, err := s.Pop(); err != nil || id == target {
+		t.Fatalf("got %d, %v after deleting sticky target %d", id, err, target)
+	}
+
+	// 无会话标识时直接使用 fallback
+	s = NewSticky("", map[uint]int{5: 1}, NewLottery(map[uint]int{5: 1}))
+	if id, err := s.Pop(); err != nil || id != 5 {
+		t.Fatalf("got %d, %v, want 5", id, err)
+	}
+}
@@ -0,0 +1,70 @@
+package balancers
+
+import (
+	"maps"
+	"slices"
+)
+
+// 按优先级分组依次使用，当前组全部失败或熔断后才进入下一组，组内使用模型配置的策略
+type Tiered struct {
+	tiers   []Balancer
+	tierOf  map[uint]int
+	current int
+}
+
+// NewTiered 按 tiers 将 items 分组，数值小的组优先；只有一组时直接返回组内策略。
+// build 接收分组的优先级与组内关联
+func NewTiered(items map[uint]int, tiers map[uint]int, build func(tier int, items map[uint]int) Balancer) Balancer {
+	groups := make(map[int]map[uint]int)
+	for key, weight := range items {
+		tier := tiers[key]
+		if groups[tier] == nil {
+			groups[tier] = make(map[uint]int)
+		}
+		groups[tier][key] = weight
+	}
+	if len(groups) <= 1 {
+		for tier, group := range groups {
+			return build(tier, group)
+		}
+		return build(0, items)
+	}
+
+	tiered := &Tiered{tierOf: make(map[uint]int, len(items))}
+	for i, tier := range slices.Sorted(maps.Keys(groups)) {
+		for key := range groups[tier] {
+			tiered.tierOf[key] = i
+		}
+		tiered.tiers = append(tiered.tiers, build(tier, groups[tier]))
+	}
+	return tiered
+}
+
+func (t *Tiered) Pop() (uint, error) {
+	var err error
+	for ; t.current < len(t.tiers); t.current++ {
+		var key uint
+		if key, err = t.tiers[t.current].Pop(); err == nil {
+			return key, nil
+		}
+	}
+	return 0, err
+}
+
+func (t *Tiered) Delete(key uint) {
+	if i, ok := t.tierOf[key]; ok {
+		t.tiers[i].Delete(key)
+	}
+}
+
+func (t *Tiered) Reduce(key uint) {
+	if i, ok := t.tierOf[key]; ok {
+		t.tiers[i].Reduce(key)
+	}
+}
+
+func (t *Tiered) Success(key uint) {
+	if i, ok := t.tierOf[key]; ok {
+		t.tiers[i].Success(key)
+	}
+}
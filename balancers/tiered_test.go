@@ -0,0 +1,41 @@
+package balancers
+
+import "testing"
+
+func TestTieredFallback(t *testing.T) {
+	items := map[uint]int{1: 1, 2: 1, 3: 100}
+	tiers := map[uint]int{1: 0, 2: 0, 3: 1}
+	b := NewTiered(items, tiers, func(_ int, items map[uint]int) Balancer { return NewLottery(items) })
+
+	// 低优先级组权重再高也不会在高优先级组可用时被选中
+	for range 20 {
+		if id, _ := b.Pop(); id == 3 {
+			t.Fatal("picked tier 1 while tier 0 is available")
+		}
+	}
+	b.Delete(1)
+	b.Delete(2)
+	if id, err := b.Pop(); err != nil || id != 3 {
+		t.Fatalf("got %d, %v, want 3", id, err)
+	}
+	b.Delete(3)
+	if _, err := b.Pop(); err == nil {
+		t.Fatal("expected error after all tiers failed")
+	}
+}
+
+func TestTieredSingleTier(t *testing.T) {
+	b := NewTiered(map[uint]int{1: 1}, map[uint]int{1: 2}, func(_ int, items map[uint]int) Balancer { return NewLottery(items) })
+	if _, ok := b.(*Lottery); !ok {
+		t.Fatalf("single tier should use the strategy directly, got %T", b)
+	}
+}
+
+func TestTieredBreakerOpen(t *testing.T) {
+	// 熔断器在构造时删除的 key 需路由到所属分组
+	b := NewTiered(map[uint]int{1: 1, 2: 1}, map[uint]int{1: 0, 2: 1}, func(_ int, items map[uint]int) Balancer { return NewPriority(items) })
+	b.Delete(1)
+	if id, err := b.Pop(); err != nil || id != 2 {
+		t.Fatalf("got %d, %v, want 2", id, err)
+	}
+}
@@ -7,17 +7,91 @@ const (
 	StyleOpenAIRes Style = "openai-res"
 	StyleAnthropic Style = "anthropic"
 	StyleGemini    Style = "gemini"
+	StyleOllama    Style = "ollama"
+)
+
+// 复用已有接口协议的提供商类型
+const (
+	// Azure OpenAI，兼容 OpenAI 协议
+	ProviderAzure = "azure"
+	// AWS Bedrock 上的 Anthropic 模型，兼容 Anthropic 协议
+	ProviderBedrock = "bedrock"
+	// Vertex AI 上的 Gemini 模型，兼容 Gemini 协议
+	ProviderVertex = "vertex"
+	// Vertex AI 上的 Anthropic 模型，兼容 Anthropic 协议
+	ProviderVertexAnthropic = "vertex-anthropic"
+	// 按配置映射规则对接的非标准接口，对外兼容 OpenAI 协议
+	ProviderCustom = "custom"
+)
+
+type Endpoint = string
+
+// 客户端请求的接口类型，决定提供商的请求路径
+const (
+	EndpointChat Endpoint = "chat"
+	// 旧版文本补全，支持 suffix 实现 FIM
+	EndpointCompletions Endpoint = "completions"
+	EndpointEmbeddings  Endpoint = "embeddings"
+	// Gemini batchEmbedContents
+	EndpointBatchEmbeddings  Endpoint = "batch_embeddings"
+	EndpointImageGenerations Endpoint = "image_generations"
+	EndpointImageEdits       Endpoint = "image_edits"
+	// 语音转文字
+	EndpointAudioTranscriptions Endpoint = "audio_transcriptions"
+	// 文字转语音
+	EndpointAudioSpeech Endpoint = "audio_speech"
+	// Cohere / Jina 风格重排序
+	EndpointRerank Endpoint = "rerank"
+)
+
+// 非 token 计量用量的单位
+const (
+	UnitImages     = "images"
+	UnitSeconds    = "seconds"
+	UnitCharacters = "characters"
+	// Cohere 重排序计费单位
+	UnitSearchUnits = "search_units"
 )
 
 const (
 	// 按权重概率抽取，类似抽签。
 	BalancerLottery = "lottery"
-	// 按顺序循环轮转，每次降低权重后移到队尾
+	// 按权重平滑轮询，轮询位置跨请求保留
 	BalancerRotor = "rotor"
+	// 按权重从高到低依次尝试，失败或限流后换下一个
+	BalancerPriority = "priority"
+	// 按近期成功率与首字延迟动态调整权重后抽取
+	BalancerSmart = "smart"
+	// 选择在途请求数与权重之比最小的关联，流式请求在响应结束前都计入在途
+	BalancerLeastConn = "least-conn"
+	// 按会话标识一致性哈希固定关联，提高提示词缓存命中率，目标不可用时按 lottery 抽取
+	BalancerSticky = "sticky"
+	// 按配置的 token 单价从低到高依次尝试
+	BalancerCheapest = "cheapest"
 	// 默认策略
 	BalancerDefault = BalancerLottery
 )
 
+// 提供商多 Key 的选择策略
+const (
+	// 按权重平滑轮询
+	KeyStrategyRoundRobin = "round-robin"
+	// 优先使用最久未被限流的 Key
+	KeyStrategyLeastRateLimited = "least-rate-limited"
+)
+
+// Batch 任务状态，与 OpenAI Batch 接口一致
+const (
+	BatchStatusValidating = "validating"
+	BatchStatusFailed     = "failed"
+	BatchStatusInProgress = "in_progress"
+	BatchStatusFinalizing = "finalizing"
+	BatchStatusCompleted  = "completed"
+	BatchStatusExpired    = "expired"
+	BatchStatusCancelling = "cancelling"
+	BatchStatusCancelled  = "cancelled"
+)
+
 const (
 	KeyPrefix = "sk-llmio-"
 	KeyLength = 32
@@ -10,4 +10,5 @@ const (
 
 const (
 	ContextKeyGeminiStream ContextKey = "gemini_stream"
+	ContextKeyEndpoint     ContextKey = "endpoint"
 )
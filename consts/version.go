@@ -1,3 +1,6 @@
 package consts
 
 var Version = "dev"
+
+// OllamaVersion 兼容的 Ollama 接口版本，/ollama/api/version 返回
+const OllamaVersion = "0.12.0"
@@ -0,0 +1,442 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	register(consts.StyleAnthropic, consts.StyleOpenAI, func() Converter { return &AnthropicToOpenAI{} })
+}
+
+// AnthropicToOpenAI 使用 OpenAI 兼容提供商响应 Anthropic Messages 客户端 (如 Claude Code)
+type AnthropicToOpenAI struct{}
+
+func (c *AnthropicToOpenAI) Request(data []byte, stream bool) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	req := chatRequest{
+		Model:  body.Get("model").String(),
+		Stream: stream,
+		User:   body.Get("metadata.user_id").String(),
+	}
+	if stream {
+		req.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+	}
+	if v := body.Get("max_tokens"); v.Exists() {
+		req.MaxTokens = lo.ToPtr(v.Int())
+	}
+	if v := body.Get("temperature"); v.Exists() {
+		req.Temperature = lo.ToPtr(v.Float())
+	}
+	if v := body.Get("top_p"); v.Exists() {
+		req.TopP = lo.ToPtr(v.Float())
+	}
+	for _, s := range body.Get("stop_sequences").Array() {
+		req.Stop = append(req.Stop, s.String())
+	}
+	if body.Get("thinking.type").String() == "enabled" {
+		req.ReasoningEffort = thinkingBudgetToEffort(body.Get("thinking.budget_tokens").Int())
+	}
+
+	if system := anthropicText(body.Get("system")); system != "" {
+		req.Messages = append(req.Messages, chatRequestMessage{Role: "system", Content: system})
+	}
+	for _, message := range body.Get("messages").Array() {
+		content := message.Get("content")
+		switch role := message.Get("role").String(); role {
+		case "user":
+			if content.Type == gjson.String {
+				req.Messages = append(req.Messages, chatRequestMessage{Role: "user", Content: content.String()})
+				continue
+			}
+			var parts []chatContentPart
+			for _, block := range content.Array() {
+				switch block.Get("type").String() {
+				case "text":
+					parts = append(parts, chatContentPart{Type: "text", Text: block.Get("text").String()})
+				case "image":
+					if url := anthropicImageURL(block.Get("source")); url != "" {
+						parts = append(parts, chatContentPart{Type: "image_url", ImageURL: &chatImageURL{URL: url}})
+					}
+				case "tool_result":
+					// tool 消息必须紧跟在 assistant 的 tool_calls 之后
+					text := anthropicText(block.Get("content"))
+					if block.Get("is_error").Bool() && text == "" {
+						text = "error"
+					}
+					req.Messages = append(req.Messages, chatRequestMessage{
+						Role:       "tool",
+						ToolCallID: block.Get("tool_use_id").String(),
+						Content:    text,
+					})
+				}
+			}
+			if len(parts) > 0 {
+				req.Messages = append(req.Messages, chatRequestMessage{Role: "user", Content: parts})
+			}
+		case "assistant":
+			if content.Type == gjson.String {
+				req.Messages = append(req.Messages, chatRequestMessage{Role: "assistant", Content: content.String()})
+				continue
+			}
+			message := chatRequestMessage{Role: "assistant"}
+			var text strings.Builder
+			for _, block := range content.Array() {
+				switch block.Get("type").String() {
+				case "text":
+					text.WriteString(block.Get("text").String())
+				case "thinking":
+					message.ReasoningContent += block.Get("thinking").String()
+				case "tool_use":
+					message.ToolCalls = append(message.ToolCalls, chatToolCall{
+						ID:       block.Get("id").String(),
+						Type:     "function",
+						Function: chatFunctionCall{Name: block.Get("name").String(), Arguments: string(toolArguments(block.Get("input").Raw))},
+					})
+				}
+			}
+			if text.Len() > 0 || len(message.ToolCalls) == 0 {
+				message.Content = text.String()
+			}
+			req.Messages = append(req.Messages, message)
+		default:
+			return nil, fmt.Errorf("unsupported message role: %s", role)
+		}
+	}
+
+	for _, tool := range body.Get("tools").Array() {
+		// 服务端工具 (如 web_search) 无 input_schema，无法转换
+		schema := tool.Get("input_schema")
+		if !schema.Exists() {
+			continue
+		}
+		req.Tools = append(req.Tools, chatTool{
+			Type: "function",
+			Function: chatFunction{
+				Name:        tool.Get("name").String(),
+				Description: tool.Get("description").String(),
+				Parameters:  json.RawMessage(schema.Raw),
+			},
+		})
+	}
+	if toolChoice := body.Get("tool_choice"); toolChoice.Exists() && len(req.Tools) > 0 {
+		switch toolChoice.Get("type").String() {
+		case "auto":
+			req.ToolChoice = "auto"
+		case "any":
+			req.ToolChoice = "required"
+		case "none":
+			req.ToolChoice = "none"
+		case "tool":
+			req.ToolChoice = newChatNamedToolChoice(toolChoice.Get("name").String())
+		}
+		if toolChoice.Get("disable_parallel_tool_use").Bool() {
+			req.ParallelToolCalls = lo.ToPtr(false)
+		}
+	}
+
+	return json.Marshal(req)
+}
+
+// anthropicText 提取字符串或 content block 数组中的文本
+func anthropicText(content gjson.Result) string {
+	if content.Type == gjson.String {
+		return content.String()
+	}
+	var texts []string
+	content.ForEach(func(_, block gjson.Result) bool {
+		if block.Get("type").String() == "text" {
+			texts = append(texts, block.Get("text").String())
+		}
+		return true
+	})
+	return strings.Join(texts, "\n")
+}
+
+func anthropicImageURL(source gjson.Result) string {
+	switch source.Get("type").String() {
+	case "base64":
+		return fmt.Sprintf("data:%s;base64,%s", source.Get("media_type").String(), source.Get("data").String())
+	case "url":
+		return source.Get("url").String()
+	default:
+		return ""
+	}
+}
+
+// thinkingBudgetToEffort 按思考预算估算 OpenAI reasoning_effort
+func thinkingBudgetToEffort(budget int64) string {
+	switch {
+	case budget < 4096:
+		return "low"
+	case budget < 16384:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// anthropicUsageFromChat OpenAI 的 prompt_tokens 包含缓存部分，Anthropic 的 input_tokens 不包含
+func anthropicUsageFromChat(usage gjson.Result) anthropicUsage {
+	cached := usage.Get("prompt_tokens_details.cached_tokens").Int()
+	return anthropicUsage{
+		InputTokens:          usage.Get("prompt_tokens").Int() - cached,
+		OutputTokens:         usage.Get("completion_tokens").Int(),
+		CacheReadInputTokens: cached,
+	}
+}
+
+func (c *AnthropicToOpenAI) Response(data []byte) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	if errMsg := body.Get("error"); errMsg.Exists() {
+		return nil, fmt.Errorf("provider error: %s", errMsg.Raw)
+	}
+	message := body.Get("choices.0.message")
+	res := anthropicResponse{
+		ID:      body.Get("id").String(),
+		Type:    "message",
+		Role:    "assistant",
+		Model:   body.Get("model").String(),
+		Content: []anthropicContent{},
+		Usage:   anthropicUsageFromChat(body.Get("usage")),
+	}
+	if reasoning := chatReasoning(message); reasoning != "" {
+		res.Content = append(res.Content, anthropicContent{Type: "thinking", Thinking: reasoning})
+	}
+	if text := chatContentText(message.Get("content")); text != "" {
+		res.Content = append(res.Content, anthropicContent{Type: "text", Text: text})
+	}
+	for _, call := range message.Get("tool_calls").Array() {
+		res.Content = append(res.Content, anthropicContent{
+			Type:  "tool_use",
+			ID:    call.Get("id").String(),
+			Name:  call.Get("function.name").String(),
+			Input: toolArguments(call.Get("function.arguments").String()),
+		})
+	}
+	res.StopReason = lo.ToPtr(openAIFinishReasonToAnthropic(body.Get("choices.0.finish_reason").String()))
+	return json.Marshal(res)
+}
+
+// chatReasoning 兼容不同厂商的推理内容字段
+func chatReasoning(message gjson.Result) string {
+	if reasoning := message.Get("reasoning_content").String(); reasoning != "" {
+		return reasoning
+	}
+	return message.Get("reasoning").String()
+}
+
+// anthropicStreamWriter 按 Anthropic 规范输出 content block 事件
+type anthropicStreamWriter struct {
+	w          io.Writer
+	blockIndex int
+	blockType  string // 当前打开的 block 类型，空表示无
+}
+
+func (s *anthropicStreamWriter) event(eventType string, payload map[string]any) error {
+	payload["type"] = eventType
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return writeSSE(s.w, eventType, data)
+}
+
+// startBlock 关闭当前 block 并打开新的 block
+func (s *anthropicStreamWriter) startBlock(blockType string, block map[string]any) error {
+	if err := s.stopBlock(); err != nil {
+		return err
+	}
+	block["type"] = blockType
+	s.blockType = blockType
+	return s.event("content_block_start", map[string]any{"index": s.blockIndex, "content_block": block})
+}
+
+func (s *anthropicStreamWriter) stopBlock() error {
+	if s.blockType == "" {
+		return nil
+	}
+	if err := s.event("content_block_stop", map[string]any{"index": s.blockIndex}); err != nil {
+		return err
+	}
+	s.blockType = ""
+	s.blockIndex++
+	return nil
+}
+
+func (s *anthropicStreamWriter) delta(delta map[string]any) error {
+	return s.event("content_block_delta", map[string]any{"index": s.blockIndex, "delta": delta})
+}
+
+// streamToolCall OpenAI 流式 tool_call 对应的 tool_use block
+type streamToolCall struct {
+	id, name string
+	args     strings.Builder // 未实时输出时缓存的参数
+	closed   bool            // block 已关闭
+}
+
+// Stream 同一时间只能打开一个 block：第一个 tool_call 实时输出参数，
+// 其余 tool_call 的参数按 index 缓存，当前 tool_use block 关闭时依次输出完整 block，
+// 避免并行 tool_calls 交错的参数写入错误的 block
+func (c *AnthropicToOpenAI) Stream(r io.Reader, w io.Writer) error {
+	var (
+		started    bool
+		stopReason = "end_turn"
+		usage      anthropicUsage
+		stream     = &anthropicStreamWriter{w: w}
+		// OpenAI tool_calls index -> tool_use block
+		tools   = map[int]*streamToolCall{}
+		pending []int // 缓存中等待输出的 tool_calls index
+		live    = -1  // 正在实时输出的 tool_calls index，-1 表示无
+	)
+	// flushTools 结束实时输出的 tool_use block，并输出缓存的 tool_calls
+	flushTools := func() error {
+		if live >= 0 {
+			tools[live].closed = true
+			live = -1
+		}
+		for _, index := range pending {
+			call := tools[index]
+			if err := stream.startBlock("tool_use", map[string]any{"id": call.id, "name": call.name, "input": map[string]any{}}); err != nil {
+				return err
+			}
+			if call.args.Len() > 0 {
+				if err := stream.delta(map[string]any{"type": "input_json_delta", "partial_json": call.args.String()}); err != nil {
+					return err
+				}
+			}
+			call.closed = true
+		}
+		pending = nil
+		return nil
+	}
+	start := func(id, model string) error {
+		if started {
+			return nil
+		}
+		started = true
+		return stream.event("message_start", map[string]any{"message": map[string]any{
+			"id":            id,
+			"type":          "message",
+			"role":          "assistant",
+			"model":         model,
+			"content":       []any{},
+			"stop_reason":   nil,
+			"stop_sequence": nil,
+			"usage":         anthropicUsage{},
+		}})
+	}
+
+	for event, err := range scanSSE(r) {
+		if err != nil {
+			return err
+		}
+		if event.Data == "[DONE]" {
+			break
+		}
+		chunk := gjson.Parse(event.Data)
+		if errMsg := chunk.Get("error"); errMsg.Exists() {
+			message := errMsg.Get("message").String()
+			if message == "" {
+				message = errMsg.String()
+			}
+			return stream.event("error", map[string]any{"error": map[string]any{"type": "api_error", "message": message}})
+		}
+		if err := start(chunk.Get("id").String(), chunk.Get("model").String()); err != nil {
+			return err
+		}
+		if u := chunk.Get("usage"); u.IsObject() && u.Get("total_tokens").Int() != 0 {
+			usage = anthropicUsageFromChat(u)
+		}
+
+		choice := chunk.Get("choices.0")
+		if !choice.Exists() {
+			continue
+		}
+		delta := choice.Get("delta")
+		if reasoning := chatReasoning(delta); reasoning != "" {
+			if stream.blockType != "thinking" {
+				if err := flushTools(); err != nil {
+					return err
+				}
+				if err := stream.startBlock("thinking", map[string]any{"thinking": ""}); err != nil {
+					return err
+				}
+			}
+			if err := stream.delta(map[string]any{"type": "thinking_delta", "thinking": reasoning}); err != nil {
+				return err
+			}
+		}
+		if text := delta.Get("content").String(); text != "" {
+			if stream.blockType != "text" {
+				if err := flushTools(); err != nil {
+					return err
+				}
+				if err := stream.startBlock("text", map[string]any{"text": ""}); err != nil {
+					return err
+				}
+			}
+			if err := stream.delta(map[string]any{"type": "text_delta", "text": text}); err != nil {
+				return err
+			}
+		}
+		for _, call := range delta.Get("tool_calls").Array() {
+			index, err := toolCallIndex(call)
+			if err != nil {
+				return err
+			}
+			tool, ok := tools[index]
+			if !ok {
+				tool = &streamToolCall{id: call.Get("id").String(), name: call.Get("function.name").String()}
+				tools[index] = tool
+				if stream.blockType == "tool_use" {
+					pending = append(pending, index)
+				} else {
+					if err := stream.startBlock("tool_use", map[string]any{"id": tool.id, "name": tool.name, "input": map[string]any{}}); err != nil {
+						return err
+					}
+					live = index
+				}
+			}
+			args := call.Get("function.arguments").String()
+			switch {
+			case args == "":
+			case index == live:
+				if err := stream.delta(map[string]any{"type": "input_json_delta", "partial_json": args}); err != nil {
+					return err
+				}
+			case tool.closed:
+				slog.Warn("drop tool call arguments after block closed", "index", index)
+			default:
+				tool.args.WriteString(args)
+			}
+		}
+		if reason := choice.Get("finish_reason").String(); reason != "" {
+			stopReason = openAIFinishReasonToAnthropic(reason)
+		}
+	}
+
+	if err := start("", ""); err != nil {
+		return err
+	}
+	if err := flushTools(); err != nil {
+		return err
+	}
+	if err := stream.stopBlock(); err != nil {
+		return err
+	}
+	if err := stream.event("message_delta", map[string]any{
+		"delta": map[string]any{"stop_reason": stopReason, "stop_sequence": nil},
+		"usage": usage,
+	}); err != nil {
+		return err
+	}
+	return stream.event("message_stop", map[string]any{})
+}
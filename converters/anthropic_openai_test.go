@@ -0,0 +1,167 @@
+package converters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestAnthropicToOpenAIRequest(t *testing.T) {
+	in := `{
+		"model": "claude-sonnet-4-5",
+		"max_tokens": 2048,
+		"system": [{"type": "text", "text": "You are Claude Code."}],
+		"thinking": {"type": "enabled", "budget_tokens": 8000},
+		"messages": [
+			{"role": "user", "content": [
+				{"type": "text", "text": "Look at this"},
+				{"type": "image", "source": {"type": "base64", "media_type": "image/png", "data": "aGVsbG8="}}
+			]},
+			{"role": "assistant", "content": [
+				{"type": "thinking", "thinking": "need a tool", "signature": "sig"},
+				{"type": "tool_use", "id": "toolu_1", "name": "Read", "input": {"path": "main.go"}}
+			]},
+			{"role": "user", "content": [
+				{"type": "tool_result", "tool_use_id": "toolu_1", "content": [{"type": "text", "text": "package main"}]},
+				{"type": "text", "text": "continue"}
+			]}
+		],
+		"tools": [
+			{"name": "Read", "description": "Read a file", "input_schema": {"type": "object"}},
+			{"type": "web_search_20250305", "name": "web_search"}
+		],
+		"tool_choice": {"type": "any", "disable_parallel_tool_use": true}
+	}`
+
+	out, err := (&AnthropicToOpenAI{}).Request([]byte(in), true)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+
+	checks := map[string]string{
+		"messages.0.role":                            "system",
+		"messages.0.content":                         "You are Claude Code.",
+		"messages.1.content.1.image_url.url":         "data:image/png;base64,aGVsbG8=",
+		"messages.2.reasoning_content":               "need a tool",
+		"messages.2.tool_calls.0.function.name":      "Read",
+		"messages.2.tool_calls.0.function.arguments": `{"path": "main.go"}`,
+		"messages.3.role":                            "tool",
+		"messages.3.tool_call_id":                    "toolu_1",
+		"messages.3.content":                         "package main",
+		"messages.4.content.0.text":                  "continue",
+		"tool_choice":                                "required",
+		"reasoning_effort":                           "medium",
+		"stream_options.include_usage":               "true",
+		"parallel_tool_calls":                        "false",
+		"tools.#":                                    "1",
+	}
+	for path, want := range checks {
+		if got := res.Get(path).String(); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestAnthropicToOpenAIStream(t *testing.T) {
+	in := strings.Join([]string{
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"reasoning_content":"hmm"}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"Read","arguments":""}}]}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{}"}}]}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[],"usage":{"prompt_tokens":30,"completion_tokens":8,"total_tokens":38,"prompt_tokens_details":{"cached_tokens":10}}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	var out strings.Builder
+	if err := (&AnthropicToOpenAI{}).Stream(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	var events []string
+	var messageDelta gjson.Result
+	for event, err := range scanSSE(strings.NewReader(out.String())) {
+		if err != nil {
+			t.Fatalf("scanSSE() unexpected error: %v", err)
+		}
+		events = append(events, event.Event)
+		if event.Event == "message_delta" {
+			messageDelta = gjson.Parse(event.Data)
+		}
+	}
+	want := []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"message_delta", "message_stop",
+	}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	if got := messageDelta.Get("delta.stop_reason").String(); got != "tool_use" {
+		t.Errorf("stop_reason = %q, want tool_use", got)
+	}
+	if got := messageDelta.Get("usage.input_tokens").Int(); got != 20 {
+		t.Errorf("input_tokens = %d, want 20", got)
+	}
+	if got := messageDelta.Get("usage.cache_read_input_tokens").Int(); got != 10 {
+		t.Errorf("cache_read_input_tokens = %d, want 10", got)
+	}
+}
+
+func TestAnthropicToOpenAIStreamParallelToolCalls(t *testing.T) {
+	in := strings.Join([]string{
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_a","function":{"name":"Read","arguments":"{\"path\":"}}]}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","function":{"name":"Grep","arguments":"{\"pattern\":"}}]}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"a.go\"}"}}]}}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"TODO\"}"}}]},"finish_reason":"tool_calls"}]}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	var out strings.Builder
+	if err := (&AnthropicToOpenAI{}).Stream(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	// 按 block 拼接 partial_json，交错的参数应写入各自的 tool_use block
+	names := map[int64]string{}
+	inputs := map[int64]string{}
+	for event, err := range scanSSE(strings.NewReader(out.String())) {
+		if err != nil {
+			t.Fatalf("scanSSE() unexpected error: %v", err)
+		}
+		data := gjson.Parse(event.Data)
+		switch event.Event {
+		case "content_block_start":
+			names[data.Get("index").Int()] = data.Get("content_block.name").String()
+		case "content_block_delta":
+			inputs[data.Get("index").Int()] += data.Get("delta.partial_json").String()
+		}
+	}
+	want := map[string]string{"Read": `{"path":"a.go"}`, "Grep": `{"pattern":"TODO"}`}
+	if len(names) != len(want) {
+		t.Fatalf("got blocks %v, want 2 tool_use blocks", names)
+	}
+	for index, name := range names {
+		if inputs[index] != want[name] {
+			t.Errorf("%s input = %s, want %s", name, inputs[index], want[name])
+		}
+	}
+}
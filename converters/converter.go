@@ -0,0 +1,163 @@
+package converters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+)
+
+const (
+	initScannerBufferSize = 1024 * 8         // 8KB
+	maxScannerBufferSize  = 1024 * 1024 * 64 // 64MB
+)
+
+// Converter 负责客户端协议与提供商协议之间的互相转换。
+// 请求方向: 客户端 -> 提供商；响应方向: 提供商 -> 客户端。
+type Converter interface {
+	// Request 将客户端请求体转换为提供商请求体
+	Request(data []byte, stream bool) ([]byte, error)
+	// Response 将提供商的非流式响应转换为客户端响应
+	Response(data []byte) ([]byte, error)
+	// Stream 将提供商的流式响应逐事件转换后写入 w
+	Stream(r io.Reader, w io.Writer) error
+}
+
+type route struct {
+	from consts.Style
+	to   consts.Style
+}
+
+var registry = map[route]func() Converter{}
+
+func register(from, to consts.Style, fn func() Converter) {
+	registry[route{from: from, to: to}] = fn
+}
+
+// New 返回从客户端协议 from 到提供商协议 to 的转换器
+func New(from, to consts.Style) (Converter, error) {
+	fn, ok := registry[route{from: from, to: to}]
+	if !ok {
+		return nil, fmt.Errorf("unsupported conversion from %s to %s", from, to)
+	}
+	return fn(), nil
+}
+
+// Targets 返回客户端协议 from 可以使用的提供商类型，包含其自身
+func Targets(from consts.Style) []consts.Style {
+	targets := []consts.Style{from}
+	for r := range registry {
+		if r.from == from {
+			targets = append(targets, r.to)
+		}
+	}
+	return targets
+}
+
+// Sources 返回可以访问提供商类型 to 的客户端协议，包含其自身
+func Sources(to consts.Style) []consts.Style {
+	sources := []consts.Style{to}
+	for r := range registry {
+		if r.to == to {
+			sources = append(sources, r.from)
+		}
+	}
+	return sources
+}
+
+// WrapBody 将提供商响应体包装为转换后的客户端响应体
+func WrapBody(converter Converter, body io.ReadCloser, stream bool) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer body.Close()
+		if stream {
+			pw.CloseWithError(converter.Stream(body, pw))
+			return
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		out, err := converter.Response(data)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		_, err = pw.Write(out)
+		pw.CloseWithError(err)
+	}()
+	return &convertedBody{PipeReader: pr, source: body}
+}
+
+type convertedBody struct {
+	*io.PipeReader
+	source io.ReadCloser
+}
+
+func (b *convertedBody) Close() error {
+	b.PipeReader.Close()
+	return b.source.Close()
+}
+
+// sseEvent 一条 SSE 事件，Event 为空表示未携带 event 字段
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// scanSSE 按空行切分 SSE 事件
+func scanSSE(r io.Reader) iter.Seq2[sseEvent, error] {
+	return func(yield func(sseEvent, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, initScannerBufferSize), maxScannerBufferSize)
+		var event sseEvent
+		var data []string
+		flush := func() bool {
+			if len(data) == 0 {
+				event = sseEvent{}
+				return true
+			}
+			event.Data = strings.Join(data, "\n")
+			ok := yield(event, nil)
+			event, data = sseEvent{}, nil
+			return ok
+		}
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if !flush() {
+					return
+				}
+			case strings.HasPrefix(line, ":"):
+			case strings.HasPrefix(line, "event:"):
+				event.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(sseEvent{}, err)
+			return
+		}
+		flush()
+	}
+}
+
+func writeSSE(w io.Writer, event string, data []byte) error {
+	var b strings.Builder
+	if event != "" {
+		b.WriteString("event: ")
+		b.WriteString(event)
+		b.WriteString("\n")
+	}
+	b.WriteString("data: ")
+	b.Write(data)
+	b.WriteString("\n\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
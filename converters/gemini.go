@@ -0,0 +1,194 @@
+package converters
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Gemini generateContent 协议的最小结构定义，供各转换器复用
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FileData         *geminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type geminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name                 string          `json:"name"`
+	Description          string          `json:"description,omitempty"`
+	ParametersJSONSchema json.RawMessage `json:"parametersJsonSchema,omitempty"`
+}
+
+type geminiToolConfig struct {
+	FunctionCallingConfig geminiFunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+type geminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature        *float64        `json:"temperature,omitempty"`
+	TopP               *float64        `json:"topP,omitempty"`
+	MaxOutputTokens    *int64          `json:"maxOutputTokens,omitempty"`
+	StopSequences      []string        `json:"stopSequences,omitempty"`
+	ResponseMimeType   string          `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema json.RawMessage `json:"responseJsonSchema,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates    []geminiCandidate `json:"candidates"`
+	UsageMetadata *geminiUsage      `json:"usageMetadata,omitempty"`
+	ModelVersion  string            `json:"modelVersion,omitempty"`
+	ResponseID    string            `json:"responseId,omitempty"`
+}
+
+type geminiCandidate struct {
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason,omitempty"`
+	Index        int           `json:"index"`
+}
+
+type geminiUsage struct {
+	PromptTokenCount        int64 `json:"promptTokenCount"`
+	CandidatesTokenCount    int64 `json:"candidatesTokenCount"`
+	TotalTokenCount         int64 `json:"totalTokenCount"`
+	CachedContentTokenCount int64 `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int64 `json:"thoughtsTokenCount,omitempty"`
+}
+
+// geminiGet 同时兼容 camelCase 与 snake_case 两种字段命名
+func geminiGet(r gjson.Result, camel, snake string) gjson.Result {
+	if v := r.Get(camel); v.Exists() {
+		return v
+	}
+	return r.Get(snake)
+}
+
+// geminiUsageFromChat OpenAI 的 completion_tokens 包含推理部分，Gemini 单独计为 thoughtsTokenCount
+func geminiUsageFromChat(usage gjson.Result) *geminiUsage {
+	if !usage.IsObject() {
+		return nil
+	}
+	thoughts := usage.Get("completion_tokens_details.reasoning_tokens").Int()
+	return &geminiUsage{
+		PromptTokenCount:        usage.Get("prompt_tokens").Int(),
+		CandidatesTokenCount:    usage.Get("completion_tokens").Int() - thoughts,
+		TotalTokenCount:         usage.Get("total_tokens").Int(),
+		CachedContentTokenCount: usage.Get("prompt_tokens_details.cached_tokens").Int(),
+		ThoughtsTokenCount:      thoughts,
+	}
+}
+
+func chatUsageFromGemini(usage gjson.Result) *chatUsage {
+	return newChatUsage(
+		geminiGet(usage, "promptTokenCount", "prompt_token_count").Int(),
+		geminiGet(usage, "candidatesTokenCount", "candidates_token_count").Int()+geminiGet(usage, "thoughtsTokenCount", "thoughts_token_count").Int(),
+		geminiGet(usage, "cachedContentTokenCount", "cached_content_token_count").Int(),
+	)
+}
+
+// geminiFinishReasonToOpenAI 将 Gemini finishReason 映射为 OpenAI finish_reason
+func geminiFinishReasonToOpenAI(reason string, toolCall bool) string {
+	switch reason {
+	case "", "STOP", "FINISH_REASON_UNSPECIFIED":
+		if toolCall {
+			return "tool_calls"
+		}
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	case "MALFORMED_FUNCTION_CALL", "UNEXPECTED_TOOL_CALL":
+		return "tool_calls"
+	default:
+		return "content_filter"
+	}
+}
+
+// openAIFinishReasonToGemini 将 OpenAI finish_reason 映射为 Gemini finishReason
+func openAIFinishReasonToGemini(reason string) string {
+	switch reason {
+	case "length":
+		return "MAX_TOKENS"
+	case "content_filter":
+		return "SAFETY"
+	default:
+		return "STOP"
+	}
+}
+
+// lowercaseSchemaTypes Gemini OpenAPI Schema 的 type 为大写 (OBJECT/STRING)，JSON Schema 需要小写
+func lowercaseSchemaTypes(raw string) json.RawMessage {
+	var schema any
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return json.RawMessage(raw)
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch node := v.(type) {
+		case map[string]any:
+			for key, value := range node {
+				if s, ok := value.(string); ok && key == "type" {
+					node[key] = strings.ToLower(s)
+					continue
+				}
+				walk(value)
+			}
+		case []any:
+			for _, value := range node {
+				walk(value)
+			}
+		}
+	}
+	walk(schema)
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return json.RawMessage(raw)
+	}
+	return data
+}
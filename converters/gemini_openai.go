@@ -0,0 +1,298 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	register(consts.StyleGemini, consts.StyleOpenAI, func() Converter { return &GeminiToOpenAI{} })
+}
+
+// GeminiToOpenAI 使用 OpenAI 兼容提供商响应 Gemini 原生接口客户端
+type GeminiToOpenAI struct{}
+
+func (c *GeminiToOpenAI) Request(data []byte, stream bool) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	req := chatRequest{Stream: stream}
+	if stream {
+		req.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+	}
+
+	config := geminiGet(body, "generationConfig", "generation_config")
+	if v := config.Get("temperature"); v.Exists() {
+		req.Temperature = lo.ToPtr(v.Float())
+	}
+	if v := geminiGet(config, "topP", "top_p"); v.Exists() {
+		req.TopP = lo.ToPtr(v.Float())
+	}
+	if v := geminiGet(config, "maxOutputTokens", "max_output_tokens"); v.Exists() {
+		req.MaxTokens = lo.ToPtr(v.Int())
+	}
+	for _, s := range geminiGet(config, "stopSequences", "stop_sequences").Array() {
+		req.Stop = append(req.Stop, s.String())
+	}
+	if schema := geminiGet(config, "responseJsonSchema", "response_json_schema"); schema.Exists() {
+		req.ResponseFormat = chatJSONSchemaFormat(json.RawMessage(schema.Raw))
+	} else if schema := geminiGet(config, "responseSchema", "response_schema"); schema.Exists() {
+		req.ResponseFormat = chatJSONSchemaFormat(lowercaseSchemaTypes(schema.Raw))
+	} else if strings.EqualFold(geminiGet(config, "responseMimeType", "response_mime_type").String(), "application/json") {
+		req.ResponseFormat = map[string]string{"type": "json_object"}
+	}
+
+	if system := geminiPartsText(geminiGet(body, "systemInstruction", "system_instruction").Get("parts")); system != "" {
+		req.Messages = append(req.Messages, chatRequestMessage{Role: "system", Content: system})
+	}
+
+	// Gemini functionResponse 一般只携带函数名，按顺序与之前生成的 tool_call id 对应
+	pendingCalls := map[string][]string{}
+	var callCount int
+	for _, content := range body.Get("contents").Array() {
+		parts := content.Get("parts").Array()
+		if content.Get("role").String() == "model" {
+			message := chatRequestMessage{Role: "assistant"}
+			var text strings.Builder
+			for _, part := range parts {
+				if call := geminiGet(part, "functionCall", "function_call"); call.Exists() {
+					id := call.Get("id").String()
+					if id == "" {
+						id = fmt.Sprintf("call_%d", callCount)
+					}
+					callCount++
+					name := call.Get("name").String()
+					pendingCalls[name] = append(pendingCalls[name], id)
+					message.ToolCalls = append(message.ToolCalls, chatToolCall{
+						ID:       id,
+						Type:     "function",
+						Function: chatFunctionCall{Name: name, Arguments: string(toolArguments(call.Get("args").Raw))},
+					})
+					continue
+				}
+				if part.Get("thought").Bool() {
+					message.ReasoningContent += part.Get("text").String()
+					continue
+				}
+				text.WriteString(part.Get("text").String())
+			}
+			if text.Len() > 0 || len(message.ToolCalls) == 0 {
+				message.Content = text.String()
+			}
+			req.Messages = append(req.Messages, message)
+			continue
+		}
+
+		var userParts []chatContentPart
+		for _, part := range parts {
+			if response := geminiGet(part, "functionResponse", "function_response"); response.Exists() {
+				name := response.Get("name").String()
+				id := response.Get("id").String()
+				if queue := pendingCalls[name]; len(queue) > 0 {
+					if id == "" {
+						id = queue[0]
+					}
+					pendingCalls[name] = queue[1:]
+				}
+				req.Messages = append(req.Messages, chatRequestMessage{
+					Role:       "tool",
+					ToolCallID: id,
+					Content:    response.Get("response").Raw,
+				})
+				continue
+			}
+			if text := part.Get("text"); text.Exists() {
+				userParts = append(userParts, chatContentPart{Type: "text", Text: text.String()})
+				continue
+			}
+			if blob := geminiGet(part, "inlineData", "inline_data"); blob.Exists() {
+				mimeType := geminiGet(blob, "mimeType", "mime_type").String()
+				if strings.HasPrefix(mimeType, "image/") {
+					userParts = append(userParts, chatContentPart{Type: "image_url", ImageURL: &chatImageURL{URL: fmt.Sprintf("data:%s;base64,%s", mimeType, blob.Get("data").String())}})
+				}
+				continue
+			}
+			if file := geminiGet(part, "fileData", "file_data"); file.Exists() {
+				userParts = append(userParts, chatContentPart{Type: "image_url", ImageURL: &chatImageURL{URL: geminiGet(file, "fileUri", "file_uri").String()}})
+			}
+		}
+		if len(userParts) > 0 {
+			req.Messages = append(req.Messages, chatRequestMessage{Role: "user", Content: userParts})
+		}
+	}
+
+	for _, tool := range body.Get("tools").Array() {
+		for _, declaration := range geminiGet(tool, "functionDeclarations", "function_declarations").Array() {
+			function := chatFunction{
+				Name:        declaration.Get("name").String(),
+				Description: declaration.Get("description").String(),
+			}
+			if schema := geminiGet(declaration, "parametersJsonSchema", "parameters_json_schema"); schema.Exists() {
+				function.Parameters = json.RawMessage(schema.Raw)
+			} else if schema := declaration.Get("parameters"); schema.Exists() {
+				function.Parameters = lowercaseSchemaTypes(schema.Raw)
+			}
+			req.Tools = append(req.Tools, chatTool{Type: "function", Function: function})
+		}
+	}
+	callingConfig := geminiGet(geminiGet(body, "toolConfig", "tool_config"), "functionCallingConfig", "function_calling_config")
+	if callingConfig.Exists() && len(req.Tools) > 0 {
+		allowed := geminiGet(callingConfig, "allowedFunctionNames", "allowed_function_names").Array()
+		switch strings.ToUpper(callingConfig.Get("mode").String()) {
+		case "AUTO":
+			req.ToolChoice = "auto"
+		case "NONE":
+			req.ToolChoice = "none"
+		case "ANY":
+			req.ToolChoice = "required"
+			if len(allowed) == 1 {
+				req.ToolChoice = newChatNamedToolChoice(allowed[0].String())
+			}
+		}
+	}
+
+	return json.Marshal(req)
+}
+
+func chatJSONSchemaFormat(schema json.RawMessage) map[string]any {
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   "response",
+			"schema": schema,
+		},
+	}
+}
+
+func geminiPartsText(parts gjson.Result) string {
+	var texts []string
+	parts.ForEach(func(_, part gjson.Result) bool {
+		if text := part.Get("text"); text.Exists() {
+			texts = append(texts, text.String())
+		}
+		return true
+	})
+	return strings.Join(texts, "\n")
+}
+
+func (c *GeminiToOpenAI) Response(data []byte) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	if errMsg := body.Get("error"); errMsg.Exists() {
+		return nil, fmt.Errorf("provider error: %s", errMsg.Raw)
+	}
+	message := body.Get("choices.0.message")
+	var parts []geminiPart
+	if reasoning := chatReasoning(message); reasoning != "" {
+		parts = append(parts, geminiPart{Text: reasoning, Thought: true})
+	}
+	if text := chatContentText(message.Get("content")); text != "" {
+		parts = append(parts, geminiPart{Text: text})
+	}
+	for _, call := range message.Get("tool_calls").Array() {
+		parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+			ID:   call.Get("id").String(),
+			Name: call.Get("function.name").String(),
+			Args: toolArguments(call.Get("function.arguments").String()),
+		}})
+	}
+	return json.Marshal(geminiResponse{
+		Candidates: []geminiCandidate{{
+			Content:      geminiContent{Role: "model", Parts: lo.Ternary(parts == nil, []geminiPart{}, parts)},
+			FinishReason: openAIFinishReasonToGemini(body.Get("choices.0.finish_reason").String()),
+		}},
+		UsageMetadata: geminiUsageFromChat(body.Get("usage")),
+		ModelVersion:  body.Get("model").String(),
+		ResponseID:    body.Get("id").String(),
+	})
+}
+
+func (c *GeminiToOpenAI) Stream(r io.Reader, w io.Writer) error {
+	type pendingCall struct {
+		id, name string
+		args     strings.Builder
+	}
+	var (
+		id, model    string
+		finishReason string
+		usage        *geminiUsage
+		// Gemini 的 functionCall 需要完整参数，缓存至结束时一次性输出
+		calls []*pendingCall
+	)
+	emit := func(parts []geminiPart, finishReason string, usage *geminiUsage) error {
+		data, err := json.Marshal(geminiResponse{
+			Candidates: []geminiCandidate{{
+				Content:      geminiContent{Role: "model", Parts: parts},
+				FinishReason: finishReason,
+			}},
+			UsageMetadata: usage,
+			ModelVersion:  model,
+			ResponseID:    id,
+		})
+		if err != nil {
+			return err
+		}
+		return writeSSE(w, "", data)
+	}
+
+	for event, err := range scanSSE(r) {
+		if err != nil {
+			return err
+		}
+		if event.Data == "[DONE]" {
+			break
+		}
+		chunk := gjson.Parse(event.Data)
+		if errMsg := chunk.Get("error"); errMsg.Exists() {
+			return writeSSE(w, "", []byte(event.Data))
+		}
+		id = lo.CoalesceOrEmpty(id, chunk.Get("id").String())
+		model = lo.CoalesceOrEmpty(model, chunk.Get("model").String())
+		if u := chunk.Get("usage"); u.IsObject() && u.Get("total_tokens").Int() != 0 {
+			usage = geminiUsageFromChat(u)
+		}
+
+		choice := chunk.Get("choices.0")
+		delta := choice.Get("delta")
+		var parts []geminiPart
+		if reasoning := chatReasoning(delta); reasoning != "" {
+			parts = append(parts, geminiPart{Text: reasoning, Thought: true})
+		}
+		if text := delta.Get("content").String(); text != "" {
+			parts = append(parts, geminiPart{Text: text})
+		}
+		for _, call := range delta.Get("tool_calls").Array() {
+			index, err := toolCallIndex(call)
+			if err != nil {
+				return err
+			}
+			for len(calls) <= index {
+				calls = append(calls, &pendingCall{})
+			}
+			calls[index].id = lo.CoalesceOrEmpty(calls[index].id, call.Get("id").String())
+			calls[index].name = lo.CoalesceOrEmpty(calls[index].name, call.Get("function.name").String())
+			calls[index].args.WriteString(call.Get("function.arguments").String())
+		}
+		if reason := choice.Get("finish_reason").String(); reason != "" {
+			finishReason = openAIFinishReasonToGemini(reason)
+		}
+		if len(parts) > 0 {
+			if err := emit(parts, "", nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	parts := []geminiPart{}
+	for _, call := range calls {
+		parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+			ID:   call.id,
+			Name: call.name,
+			Args: toolArguments(call.args.String()),
+		}})
+	}
+	return emit(parts, lo.CoalesceOrEmpty(finishReason, "STOP"), usage)
+}
@@ -0,0 +1,56 @@
+package converters
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Ollama /api/chat 协议的最小结构定义，供各转换器复用
+
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	CreatedAt       string        `json:"created_at"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason,omitempty"`
+	PromptEvalCount int64         `json:"prompt_eval_count,omitempty"`
+	EvalCount       int64         `json:"eval_count,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function ollamaFunctionCall `json:"function"`
+}
+
+type ollamaFunctionCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// openAIFinishReasonToOllama Ollama 仅区分正常结束与长度截断
+func openAIFinishReasonToOllama(reason string) string {
+	if reason == "length" {
+		return "length"
+	}
+	return "stop"
+}
+
+func ollamaTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func writeNDJSON(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
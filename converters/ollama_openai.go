@@ -0,0 +1,246 @@
+package converters
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	register(consts.StyleOllama, consts.StyleOpenAI, func() Converter { return &OllamaToOpenAI{} })
+}
+
+// OllamaToOpenAI 使用 OpenAI 兼容提供商响应 Ollama /api/chat 客户端
+type OllamaToOpenAI struct {
+	model string
+}
+
+func (c *OllamaToOpenAI) Request(data []byte, stream bool) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	c.model = body.Get("model").String()
+	req := chatRequest{
+		Model:  c.model,
+		Stream: stream,
+	}
+	if stream {
+		req.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+	}
+
+	options := body.Get("options")
+	if v := options.Get("temperature"); v.Exists() {
+		req.Temperature = lo.ToPtr(v.Float())
+	}
+	if v := options.Get("top_p"); v.Exists() {
+		req.TopP = lo.ToPtr(v.Float())
+	}
+	// num_predict 为 -1 表示不限制
+	if v := options.Get("num_predict"); v.Int() > 0 {
+		req.MaxTokens = lo.ToPtr(v.Int())
+	}
+	for _, s := range options.Get("stop").Array() {
+		req.Stop = append(req.Stop, s.String())
+	}
+	switch format := body.Get("format"); {
+	case format.IsObject():
+		req.ResponseFormat = chatJSONSchemaFormat(json.RawMessage(format.Raw))
+	case format.String() == "json":
+		req.ResponseFormat = map[string]string{"type": "json_object"}
+	}
+	switch think := body.Get("think"); think.Type {
+	case gjson.True:
+		req.ReasoningEffort = "medium"
+	case gjson.String:
+		req.ReasoningEffort = think.String()
+	}
+
+	for _, tool := range body.Get("tools").Array() {
+		if tool.Get("type").String() != "function" {
+			continue
+		}
+		req.Tools = append(req.Tools, chatTool{
+			Type: "function",
+			Function: chatFunction{
+				Name:        tool.Get("function.name").String(),
+				Description: tool.Get("function.description").String(),
+				Parameters:  json.RawMessage(tool.Get("function.parameters").Raw),
+			},
+		})
+	}
+
+	// Ollama 的工具调用没有 id，按调用顺序生成并以函数名与 tool 消息对应
+	var pending []chatToolCall
+	var callCount int
+	for _, message := range body.Get("messages").Array() {
+		content := message.Get("content").String()
+		switch role := message.Get("role").String(); role {
+		case "system":
+			req.Messages = append(req.Messages, chatRequestMessage{Role: "system", Content: content})
+		case "user":
+			images := message.Get("images").Array()
+			if len(images) == 0 {
+				req.Messages = append(req.Messages, chatRequestMessage{Role: "user", Content: content})
+				continue
+			}
+			parts := []chatContentPart{{Type: "text", Text: content}}
+			for _, image := range images {
+				parts = append(parts, chatContentPart{Type: "image_url", ImageURL: &chatImageURL{URL: ollamaImageURL(image.String())}})
+			}
+			req.Messages = append(req.Messages, chatRequestMessage{Role: "user", Content: parts})
+		case "assistant":
+			msg := chatRequestMessage{Role: "assistant", Content: content}
+			for _, call := range message.Get("tool_calls").Array() {
+				callCount++
+				toolCall := chatToolCall{
+					ID:   fmt.Sprintf("call_%d", callCount),
+					Type: "function",
+					Function: chatFunctionCall{
+						Name:      call.Get("function.name").String(),
+						Arguments: lo.CoalesceOrEmpty(call.Get("function.arguments").Raw, "{}"),
+					},
+				}
+				msg.ToolCalls = append(msg.ToolCalls, toolCall)
+				pending = append(pending, toolCall)
+			}
+			req.Messages = append(req.Messages, msg)
+		case "tool":
+			name := lo.CoalesceOrEmpty(message.Get("tool_name").String(), message.Get("name").String())
+			_, index, ok := lo.FindIndexOf(pending, func(call chatToolCall) bool {
+				return name == "" || call.Function.Name == name
+			})
+			if !ok {
+				return nil, fmt.Errorf("tool message without matching tool call: %s", name)
+			}
+			req.Messages = append(req.Messages, chatRequestMessage{Role: "tool", Content: content, ToolCallID: pending[index].ID})
+			pending = append(pending[:index], pending[index+1:]...)
+		default:
+			return nil, fmt.Errorf("unsupported message role: %s", role)
+		}
+	}
+
+	return json.Marshal(req)
+}
+
+// ollamaImageURL Ollama 图片为不带前缀的 base64，按内容推断类型转为 data URL
+func ollamaImageURL(data string) string {
+	head, _ := base64.StdEncoding.DecodeString(data[:min(len(data), 64)/4*4])
+	return fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(head), data)
+}
+
+func ollamaToolCallsFromChat(calls []gjson.Result) []ollamaToolCall {
+	var toolCalls []ollamaToolCall
+	for _, call := range calls {
+		toolCalls = append(toolCalls, ollamaToolCall{Function: ollamaFunctionCall{
+			Name:      call.Get("function.name").String(),
+			Arguments: toolArguments(call.Get("function.arguments").String()),
+		}})
+	}
+	return toolCalls
+}
+
+func (c *OllamaToOpenAI) Response(data []byte) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	if errMsg := body.Get("error"); errMsg.Exists() {
+		return nil, fmt.Errorf("provider error: %s", errMsg.Raw)
+	}
+	message := body.Get("choices.0.message")
+	return json.Marshal(ollamaResponse{
+		Model:     lo.CoalesceOrEmpty(c.model, body.Get("model").String()),
+		CreatedAt: ollamaTimestamp(),
+		Message: ollamaMessage{
+			Role:      "assistant",
+			Content:   chatContentText(message.Get("content")),
+			Thinking:  chatReasoning(message),
+			ToolCalls: ollamaToolCallsFromChat(message.Get("tool_calls").Array()),
+		},
+		Done:            true,
+		DoneReason:      openAIFinishReasonToOllama(body.Get("choices.0.finish_reason").String()),
+		PromptEvalCount: body.Get("usage.prompt_tokens").Int(),
+		EvalCount:       body.Get("usage.completion_tokens").Int(),
+	})
+}
+
+func (c *OllamaToOpenAI) Stream(r io.Reader, w io.Writer) error {
+	type pendingCall struct {
+		name string
+		args strings.Builder
+	}
+	var (
+		model        = c.model
+		finishReason string
+		usage        gjson.Result
+		// Ollama 的 tool_calls 需要完整参数，缓存至结束时一次性输出
+		calls []*pendingCall
+	)
+	emit := func(message ollamaMessage) error {
+		return writeNDJSON(w, ollamaResponse{Model: model, CreatedAt: ollamaTimestamp(), Message: message})
+	}
+
+	for event, err := range scanSSE(r) {
+		if err != nil {
+			return err
+		}
+		if event.Data == "[DONE]" {
+			break
+		}
+		chunk := gjson.Parse(event.Data)
+		if errMsg := chunk.Get("error"); errMsg.Exists() {
+			return writeNDJSON(w, map[string]string{"error": lo.CoalesceOrEmpty(errMsg.Get("message").String(), errMsg.String())})
+		}
+		model = lo.CoalesceOrEmpty(model, chunk.Get("model").String())
+		if u := chunk.Get("usage"); u.IsObject() && u.Get("total_tokens").Int() != 0 {
+			usage = u
+		}
+
+		choice := chunk.Get("choices.0")
+		delta := choice.Get("delta")
+		for _, call := range delta.Get("tool_calls").Array() {
+			index, err := toolCallIndex(call)
+			if err != nil {
+				return err
+			}
+			for len(calls) <= index {
+				calls = append(calls, &pendingCall{})
+			}
+			calls[index].name = lo.CoalesceOrEmpty(calls[index].name, call.Get("function.name").String())
+			calls[index].args.WriteString(call.Get("function.arguments").String())
+		}
+		if reason := choice.Get("finish_reason").String(); reason != "" {
+			finishReason = reason
+		}
+		message := ollamaMessage{Role: "assistant", Content: delta.Get("content").String(), Thinking: chatReasoning(delta)}
+		if message.Content != "" || message.Thinking != "" {
+			if err := emit(message); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(calls) > 0 {
+		message := ollamaMessage{Role: "assistant"}
+		for _, call := range calls {
+			message.ToolCalls = append(message.ToolCalls, ollamaToolCall{Function: ollamaFunctionCall{
+				Name:      call.name,
+				Arguments: toolArguments(call.args.String()),
+			}})
+		}
+		if err := emit(message); err != nil {
+			return err
+		}
+	}
+	return writeNDJSON(w, ollamaResponse{
+		Model:           model,
+		CreatedAt:       ollamaTimestamp(),
+		Message:         ollamaMessage{Role: "assistant"},
+		Done:            true,
+		DoneReason:      openAIFinishReasonToOllama(finishReason),
+		PromptEvalCount: usage.Get("prompt_tokens").Int(),
+		EvalCount:       usage.Get("completion_tokens").Int(),
+	})
+}
@@ -0,0 +1,99 @@
+package converters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestOllamaToOpenAIRequest(t *testing.T) {
+	in := `{
+		"model": "qwen3",
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": "What is this?", "images": ["iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="]},
+			{"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "get_weather", "arguments": {"city": "Nanjing"}}}]},
+			{"role": "tool", "content": "18C", "tool_name": "get_weather"}
+		],
+		"tools": [{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object"}}}],
+		"format": "json",
+		"options": {"temperature": 0.2, "num_predict": 64, "stop": ["\n\n"]}
+	}`
+
+	out, err := (&OllamaToOpenAI{}).Request([]byte(in), true)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+	checks := map[string]string{
+		"messages.0.role":                       "system",
+		"messages.1.content.1.image_url.url":    "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==",
+		"messages.2.tool_calls.0.id":            "call_1",
+		"messages.2.tool_calls.0.function.name": "get_weather",
+		"messages.3.tool_call_id":               "call_1",
+		"tools.0.function.name":                 "get_weather",
+		"response_format.type":                  "json_object",
+		"temperature":                           "0.2",
+		"max_tokens":                            "64",
+		"stop.0":                                "\n\n",
+		"stream_options.include_usage":          "true",
+	}
+	for path, want := range checks {
+		if got := res.Get(path).String(); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+	if got := gjson.Parse(res.Get("messages.2.tool_calls.0.function.arguments").String()).Get("city").String(); got != "Nanjing" {
+		t.Errorf("arguments city = %q", got)
+	}
+}
+
+func TestOllamaToOpenAIStream(t *testing.T) {
+	in := strings.Join([]string{
+		`data: {"id":"c1","model":"qwen3","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		``,
+		`data: {"id":"c1","model":"qwen3","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		``,
+		`data: {"id":"c1","model":"qwen3","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"f","arguments":"{\"a\":"}}]}}]}`,
+		``,
+		`data: {"id":"c1","model":"qwen3","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"1}"}}]},"finish_reason":"tool_calls"}]}`,
+		``,
+		`data: {"id":"c1","model":"qwen3","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	converter := &OllamaToOpenAI{}
+	if _, err := converter.Request([]byte(`{"model":"qwen3","messages":[{"role":"user","content":"hi"}]}`), true); err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	var out strings.Builder
+	if err := converter.Stream(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), out.String())
+	}
+	if got := gjson.Get(lines[0], "message.content").String(); got != "Hel" {
+		t.Errorf("first content = %q", got)
+	}
+	if got := gjson.Get(lines[2], "message.tool_calls.0.function.arguments.a").Int(); got != 1 {
+		t.Errorf("tool call arguments = %s", lines[2])
+	}
+	last := gjson.Parse(lines[3])
+	if !last.Get("done").Bool() || last.Get("prompt_eval_count").Int() != 3 || last.Get("eval_count").Int() != 4 || last.Get("model").String() != "qwen3" {
+		t.Errorf("unexpected final line: %s", lines[3])
+	}
+}
+
+func TestOllamaToOpenAIStreamInvalidToolIndex(t *testing.T) {
+	in := `data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":-1,"id":"call_1","function":{"name":"f","arguments":"{}"}}]}}]}` + "\n\n"
+	var out strings.Builder
+	if err := (&OllamaToOpenAI{}).Stream(strings.NewReader(in), &out); err == nil {
+		t.Error("Stream() with negative tool call index should fail")
+	}
+}
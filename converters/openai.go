@@ -0,0 +1,205 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// OpenAI Chat Completions 协议的最小结构定义，供各转换器复用
+
+type chatRequest struct {
+	Model             string               `json:"model"`
+	Messages          []chatRequestMessage `json:"messages"`
+	MaxTokens         *int64               `json:"max_tokens,omitempty"`
+	Temperature       *float64             `json:"temperature,omitempty"`
+	TopP              *float64             `json:"top_p,omitempty"`
+	Stop              []string             `json:"stop,omitempty"`
+	Stream            bool                 `json:"stream,omitempty"`
+	StreamOptions     *chatStreamOptions   `json:"stream_options,omitempty"`
+	Tools             []chatTool           `json:"tools,omitempty"`
+	ToolChoice        any                  `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool                `json:"parallel_tool_calls,omitempty"`
+	ReasoningEffort   string               `json:"reasoning_effort,omitempty"`
+	ResponseFormat    any                  `json:"response_format,omitempty"`
+	User              string               `json:"user,omitempty"`
+}
+
+type chatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatRequestMessage struct {
+	Role             string         `json:"role"`
+	Content          any            `json:"content,omitempty"` // string 或 []chatContentPart
+	ReasoningContent string         `json:"reasoning_content,omitempty"`
+	ToolCalls        []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string         `json:"tool_call_id,omitempty"`
+}
+
+type chatContentPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *chatImageURL `json:"image_url,omitempty"`
+}
+
+type chatImageURL struct {
+	URL string `json:"url"`
+}
+
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+type chatFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+type chatNamedToolChoice struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+func newChatNamedToolChoice(name string) chatNamedToolChoice {
+	choice := chatNamedToolChoice{Type: "function"}
+	choice.Function.Name = name
+	return choice
+}
+
+type chatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage,omitempty"`
+}
+
+type chatChoice struct {
+	Index        int          `json:"index"`
+	Message      *chatMessage `json:"message,omitempty"`
+	Delta        *chatMessage `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+type chatMessage struct {
+	Role             string         `json:"role,omitempty"`
+	Content          *string        `json:"content,omitempty"`
+	ReasoningContent string         `json:"reasoning_content,omitempty"`
+	ToolCalls        []chatToolCall `json:"tool_calls,omitempty"`
+}
+
+type chatToolCall struct {
+	Index    *int             `json:"index,omitempty"`
+	ID       string           `json:"id,omitempty"`
+	Type     string           `json:"type,omitempty"`
+	Function chatFunctionCall `json:"function"`
+}
+
+type chatFunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+type chatUsage struct {
+	PromptTokens        int64                    `json:"prompt_tokens"`
+	CompletionTokens    int64                    `json:"completion_tokens"`
+	TotalTokens         int64                    `json:"total_tokens"`
+	PromptTokensDetails *chatPromptTokensDetails `json:"prompt_tokens_details,omitempty"`
+}
+
+type chatPromptTokensDetails struct {
+	CachedTokens int64 `json:"cached_tokens"`
+}
+
+func newChatChunk(id, model string, created int64) chatCompletion {
+	return chatCompletion{
+		ID:      id,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   model,
+		Choices: []chatChoice{},
+	}
+}
+
+func newChatUsage(prompt, completion, cached int64) *chatUsage {
+	usage := &chatUsage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+	if cached > 0 {
+		usage.PromptTokensDetails = &chatPromptTokensDetails{CachedTokens: cached}
+	}
+	return usage
+}
+
+func writeChatChunk(w io.Writer, chunk chatCompletion) error {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	return writeSSE(w, "", data)
+}
+
+func writeChatDone(w io.Writer) error {
+	return writeSSE(w, "", []byte("[DONE]"))
+}
+
+// chatContentText 提取 OpenAI 消息 content 中的纯文本，兼容字符串与 parts 数组
+func chatContentText(content gjson.Result) string {
+	if content.Type == gjson.String {
+		return content.String()
+	}
+	var texts []string
+	content.ForEach(func(_, part gjson.Result) bool {
+		switch part.Get("type").String() {
+		case "text", "input_text", "output_text":
+			texts = append(texts, part.Get("text").String())
+		}
+		return true
+	})
+	return strings.Join(texts, "\n")
+}
+
+// maxToolCallIndex 流式 tool_calls 允许的最大 index，避免异常上游导致越界或超大分配
+const maxToolCallIndex = 127
+
+// toolCallIndex 返回流式 tool_call 的 index，超出 [0, maxToolCallIndex] 时返回错误
+func toolCallIndex(call gjson.Result) (int, error) {
+	index := call.Get("index").Int()
+	if index < 0 || index > maxToolCallIndex {
+		return 0, fmt.Errorf("invalid tool call index: %d", index)
+	}
+	return int(index), nil
+}
+
+// parseDataURL 解析 data:{mime};base64,{data} 形式的图片地址
+func parseDataURL(url string) (mimeType string, data string, ok bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return "", "", false
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return "", "", false
+	}
+	mimeType, isBase64 := strings.CutSuffix(meta, ";base64")
+	if !isBase64 {
+		return "", "", false
+	}
+	return mimeType, data, true
+}
+
+func unixNow() int64 {
+	return time.Now().Unix()
+}
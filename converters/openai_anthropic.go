@@ -0,0 +1,335 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	register(consts.StyleOpenAI, consts.StyleAnthropic, func() Converter { return &OpenAIToAnthropic{} })
+}
+
+// OpenAIToAnthropic 使用 Anthropic 提供商响应 OpenAI Chat Completions 客户端
+type OpenAIToAnthropic struct{}
+
+func (c *OpenAIToAnthropic) Request(data []byte, stream bool) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	req := anthropicRequest{
+		Model:     body.Get("model").String(),
+		MaxTokens: DefaultMaxTokens,
+		Stream:    stream,
+	}
+	if v := body.Get("max_completion_tokens"); v.Exists() {
+		req.MaxTokens = v.Int()
+	} else if v := body.Get("max_tokens"); v.Exists() {
+		req.MaxTokens = v.Int()
+	}
+	if v := body.Get("temperature"); v.Exists() {
+		// OpenAI 取值范围 0~2，Anthropic 取值范围 0~1
+		req.Temperature = lo.ToPtr(min(v.Float(), 1))
+	}
+	if v := body.Get("top_p"); v.Exists() {
+		req.TopP = lo.ToPtr(v.Float())
+	}
+	if stop := body.Get("stop"); stop.IsArray() {
+		for _, s := range stop.Array() {
+			req.StopSequences = append(req.StopSequences, s.String())
+		}
+	} else if stop.String() != "" {
+		req.StopSequences = []string{stop.String()}
+	}
+	if user := body.Get("user").String(); user != "" {
+		req.Metadata = &anthropicMetadata{UserID: user}
+	}
+
+	for _, message := range body.Get("messages").Array() {
+		switch role := message.Get("role").String(); role {
+		case "system", "developer":
+			if text := chatContentText(message.Get("content")); text != "" {
+				req.System = append(req.System, anthropicContent{Type: "text", Text: text})
+			}
+		case "user":
+			req.Messages = appendAnthropicMessage(req.Messages, "user", openAIUserContentToAnthropic(message.Get("content"))...)
+		case "assistant":
+			var blocks []anthropicContent
+			if text := chatContentText(message.Get("content")); text != "" {
+				blocks = append(blocks, anthropicContent{Type: "text", Text: text})
+			}
+			for _, call := range message.Get("tool_calls").Array() {
+				blocks = append(blocks, anthropicContent{
+					Type:  "tool_use",
+					ID:    call.Get("id").String(),
+					Name:  call.Get("function.name").String(),
+					Input: toolArguments(call.Get("function.arguments").String()),
+				})
+			}
+			req.Messages = appendAnthropicMessage(req.Messages, "assistant", blocks...)
+		case "tool":
+			req.Messages = appendAnthropicMessage(req.Messages, "user", anthropicContent{
+				Type:      "tool_result",
+				ToolUseID: message.Get("tool_call_id").String(),
+				Content:   chatContentText(message.Get("content")),
+			})
+		default:
+			return nil, fmt.Errorf("unsupported message role: %s", role)
+		}
+	}
+
+	for _, tool := range body.Get("tools").Array() {
+		if tool.Get("type").String() != "function" {
+			continue
+		}
+		schema := json.RawMessage(tool.Get("function.parameters").Raw)
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        tool.Get("function.name").String(),
+			Description: tool.Get("function.description").String(),
+			InputSchema: schema,
+		})
+	}
+	if toolChoice := body.Get("tool_choice"); toolChoice.Exists() && len(req.Tools) > 0 {
+		switch toolChoice.String() {
+		case "auto":
+			req.ToolChoice = &anthropicToolChoice{Type: "auto"}
+		case "none":
+			req.ToolChoice = &anthropicToolChoice{Type: "none"}
+		case "required":
+			req.ToolChoice = &anthropicToolChoice{Type: "any"}
+		default:
+			if name := toolChoice.Get("function.name").String(); name != "" {
+				req.ToolChoice = &anthropicToolChoice{Type: "tool", Name: name}
+			}
+		}
+	}
+	if parallel := body.Get("parallel_tool_calls"); parallel.Exists() && !parallel.Bool() && len(req.Tools) > 0 {
+		if req.ToolChoice == nil {
+			req.ToolChoice = &anthropicToolChoice{Type: "auto"}
+		}
+		req.ToolChoice.DisableParallelToolUse = true
+	}
+
+	// Anthropic 无 response_format，通过系统提示约束输出格式
+	if instruction := responseFormatInstruction(body.Get("response_format")); instruction != "" {
+		req.System = append(req.System, anthropicContent{Type: "text", Text: instruction})
+	}
+
+	return json.Marshal(req)
+}
+
+func openAIUserContentToAnthropic(content gjson.Result) []anthropicContent {
+	if content.Type == gjson.String {
+		if content.String() == "" {
+			return nil
+		}
+		return []anthropicContent{{Type: "text", Text: content.String()}}
+	}
+	var blocks []anthropicContent
+	content.ForEach(func(_, part gjson.Result) bool {
+		switch part.Get("type").String() {
+		case "text":
+			if text := part.Get("text").String(); text != "" {
+				blocks = append(blocks, anthropicContent{Type: "text", Text: text})
+			}
+		case "image_url":
+			url := part.Get("image_url.url").String()
+			if url == "" {
+				url = part.Get("image_url").String()
+			}
+			if mimeType, data, ok := parseDataURL(url); ok {
+				blocks = append(blocks, anthropicContent{Type: "image", Source: &anthropicImageSource{Type: "base64", MediaType: mimeType, Data: data}})
+			} else {
+				blocks = append(blocks, anthropicContent{Type: "image", Source: &anthropicImageSource{Type: "url", URL: url}})
+			}
+		}
+		return true
+	})
+	return blocks
+}
+
+// appendAnthropicMessage Anthropic 要求 user/assistant 交替出现，相同角色的连续消息合并为一条
+func appendAnthropicMessage(messages []anthropicMessage, role string, blocks ...anthropicContent) []anthropicMessage {
+	if len(blocks) == 0 {
+		return messages
+	}
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		messages[n-1].Content = append(messages[n-1].Content, blocks...)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: blocks})
+}
+
+// toolArguments 将 OpenAI 字符串形式的参数转换为 JSON 对象
+func toolArguments(arguments string) json.RawMessage {
+	if strings.TrimSpace(arguments) == "" || !gjson.Valid(arguments) {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(arguments)
+}
+
+// responseFormatInstruction 将 OpenAI response_format 转换为提示词
+func responseFormatInstruction(format gjson.Result) string {
+	switch format.Get("type").String() {
+	case "json_object":
+		return "Respond only with a valid JSON object, without any other text."
+	case "json_schema":
+		return "Respond only with a valid JSON object that matches the following JSON schema, without any other text:\n" + format.Get("json_schema.schema").Raw
+	default:
+		return ""
+	}
+}
+
+func (c *OpenAIToAnthropic) Response(data []byte) ([]byte, error) {
+	var res anthropicResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	message := chatMessage{Role: "assistant"}
+	for _, block := range res.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "thinking":
+			message.ReasoningContent += block.Thinking
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, chatToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: chatFunctionCall{Name: block.Name, Arguments: string(toolArguments(string(block.Input)))},
+			})
+		}
+	}
+	if text.Len() > 0 || len(message.ToolCalls) == 0 {
+		message.Content = lo.ToPtr(text.String())
+	}
+	return json.Marshal(chatCompletion{
+		ID:      res.ID,
+		Object:  "chat.completion",
+		Created: unixNow(),
+		Model:   res.Model,
+		Choices: []chatChoice{{
+			Index:        0,
+			Message:      &message,
+			FinishReason: lo.ToPtr(anthropicStopReasonToOpenAI(lo.FromPtr(res.StopReason))),
+		}},
+		Usage: res.Usage.chatUsage(),
+	})
+}
+
+func (c *OpenAIToAnthropic) Stream(r io.Reader, w io.Writer) error {
+	var (
+		id, model string
+		usage     anthropicUsage
+		done      bool
+		created   = unixNow()
+		// Anthropic content block index -> OpenAI tool_calls index
+		toolIndexes = map[int64]int{}
+	)
+	emit := func(delta chatMessage, finishReason *string) error {
+		chunk := newChatChunk(id, model, created)
+		chunk.Choices = []chatChoice{{Index: 0, Delta: &delta, FinishReason: finishReason}}
+		return writeChatChunk(w, chunk)
+	}
+
+	for event, err := range scanSSE(r) {
+		if err != nil {
+			return err
+		}
+		data := gjson.Parse(event.Data)
+		eventType := event.Event
+		if eventType == "" {
+			eventType = data.Get("type").String()
+		}
+		switch eventType {
+		case "message_start":
+			id = data.Get("message.id").String()
+			model = data.Get("message.model").String()
+			if err := json.Unmarshal([]byte(data.Get("message.usage").Raw), &usage); err != nil {
+				usage = anthropicUsage{}
+			}
+			if err := emit(chatMessage{Role: "assistant", Content: lo.ToPtr("")}, nil); err != nil {
+				return err
+			}
+		case "content_block_start":
+			block := data.Get("content_block")
+			if block.Get("type").String() != "tool_use" {
+				continue
+			}
+			index := len(toolIndexes)
+			toolIndexes[data.Get("index").Int()] = index
+			if err := emit(chatMessage{ToolCalls: []chatToolCall{{
+				Index:    lo.ToPtr(index),
+				ID:       block.Get("id").String(),
+				Type:     "function",
+				Function: chatFunctionCall{Name: block.Get("name").String()},
+			}}}, nil); err != nil {
+				return err
+			}
+		case "content_block_delta":
+			delta := data.Get("delta")
+			var message chatMessage
+			switch delta.Get("type").String() {
+			case "text_delta":
+				message.Content = lo.ToPtr(delta.Get("text").String())
+			case "thinking_delta":
+				message.ReasoningContent = delta.Get("thinking").String()
+			case "input_json_delta":
+				index, ok := toolIndexes[data.Get("index").Int()]
+				if !ok {
+					continue
+				}
+				message.ToolCalls = []chatToolCall{{
+					Index:    lo.ToPtr(index),
+					Function: chatFunctionCall{Arguments: delta.Get("partial_json").String()},
+				}}
+			default:
+				continue
+			}
+			if err := emit(message, nil); err != nil {
+				return err
+			}
+		case "message_delta":
+			if v := data.Get("usage.output_tokens"); v.Exists() {
+				usage.OutputTokens = v.Int()
+			}
+			if v := data.Get("usage.input_tokens"); v.Int() != 0 {
+				usage.InputTokens = v.Int()
+			}
+			if v := data.Get("usage.cache_read_input_tokens"); v.Int() != 0 {
+				usage.CacheReadInputTokens = v.Int()
+			}
+			if v := data.Get("usage.cache_creation_input_tokens"); v.Int() != 0 {
+				usage.CacheCreationInputTokens = v.Int()
+			}
+			if reason := data.Get("delta.stop_reason"); reason.Exists() {
+				if err := emit(chatMessage{}, lo.ToPtr(anthropicStopReasonToOpenAI(reason.String()))); err != nil {
+					return err
+				}
+			}
+		case "message_stop":
+			chunk := newChatChunk(id, model, created)
+			chunk.Usage = usage.chatUsage()
+			if err := writeChatChunk(w, chunk); err != nil {
+				return err
+			}
+			done = true
+			if err := writeChatDone(w); err != nil {
+				return err
+			}
+		case "error":
+			return writeSSE(w, "", []byte(event.Data))
+		}
+	}
+	if !done {
+		return writeChatDone(w)
+	}
+	return nil
+}
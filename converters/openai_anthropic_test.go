@@ -0,0 +1,174 @@
+package converters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIToAnthropicRequest(t *testing.T) {
+	in := `{
+		"model": "claude-sonnet-4-5",
+		"max_tokens": 1024,
+		"temperature": 1.5,
+		"stop": "END",
+		"messages": [
+			{"role": "system", "content": "You are a helpful assistant."},
+			{"role": "user", "content": [
+				{"type": "text", "text": "What is in this image?"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,aGVsbG8="}}
+			]},
+			{"role": "assistant", "content": null, "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"location\":\"Nanjing\"}"}}
+			]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "sunny"}
+		],
+		"tools": [
+			{"type": "function", "function": {"name": "get_weather", "description": "Get weather", "parameters": {"type": "object", "properties": {"location": {"type": "string"}}}}}
+		],
+		"tool_choice": "required",
+		"response_format": {"type": "json_object"}
+	}`
+
+	out, err := (&OpenAIToAnthropic{}).Request([]byte(in), true)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+
+	checks := map[string]any{
+		"max_tokens":                             int64(1024),
+		"temperature":                            float64(1),
+		"stream":                                 true,
+		"stop_sequences.0":                       "END",
+		"system.0.text":                          "You are a helpful assistant.",
+		"messages.#":                             int64(3),
+		"messages.0.content.1.type":              "image",
+		"messages.0.content.1.source.media_type": "image/png",
+		"messages.1.content.0.type":              "tool_use",
+		"messages.1.content.0.input.location":    "Nanjing",
+		"messages.2.role":                        "user",
+		"messages.2.content.0.type":              "tool_result",
+		"messages.2.content.0.tool_use_id":       "call_1",
+		"tools.0.input_schema.type":              "object",
+		"tool_choice.type":                       "any",
+	}
+	for path, want := range checks {
+		if got := res.Get(path).Value(); !equalJSONValue(got, want) {
+			t.Errorf("%s = %v, want %v", path, got, want)
+		}
+	}
+	if !strings.Contains(res.Get("system.1.text").String(), "JSON") {
+		t.Errorf("expected response_format instruction in system prompt, got %s", res.Get("system").Raw)
+	}
+}
+
+func TestOpenAIToAnthropicResponse(t *testing.T) {
+	in := `{
+		"id": "msg_1",
+		"type": "message",
+		"role": "assistant",
+		"model": "claude-sonnet-4-5",
+		"content": [
+			{"type": "text", "text": "Let me check."},
+			{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"location": "Nanjing"}}
+		],
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 10, "output_tokens": 5, "cache_read_input_tokens": 20}
+	}`
+
+	out, err := (&OpenAIToAnthropic{}).Response([]byte(in))
+	if err != nil {
+		t.Fatalf("Response() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+	if got := res.Get("choices.0.message.content").String(); got != "Let me check." {
+		t.Errorf("content = %q", got)
+	}
+	if got := res.Get("choices.0.message.tool_calls.0.function.arguments").String(); got != `{"location": "Nanjing"}` {
+		t.Errorf("arguments = %q", got)
+	}
+	if got := res.Get("choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Errorf("finish_reason = %q", got)
+	}
+	if got := res.Get("usage.prompt_tokens").Int(); got != 30 {
+		t.Errorf("prompt_tokens = %d, want 30", got)
+	}
+	if got := res.Get("usage.prompt_tokens_details.cached_tokens").Int(); got != 20 {
+		t.Errorf("cached_tokens = %d, want 20", got)
+	}
+}
+
+func TestOpenAIToAnthropicStream(t *testing.T) {
+	in := strings.Join([]string{
+		`event: message_start`,
+		`data: {"type":"message_start","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":1}}}`,
+		``,
+		`event: content_block_start`,
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`,
+		``,
+		`event: content_block_start`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"location\":"}}`,
+		``,
+		`event: message_delta`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":7}}`,
+		``,
+		`event: message_stop`,
+		`data: {"type":"message_stop"}`,
+		``,
+	}, "\n")
+
+	var out strings.Builder
+	if err := (&OpenAIToAnthropic{}).Stream(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	var chunks []gjson.Result
+	for event, err := range scanSSE(strings.NewReader(out.String())) {
+		if err != nil {
+			t.Fatalf("scanSSE() unexpected error: %v", err)
+		}
+		if event.Data == "[DONE]" {
+			continue
+		}
+		chunks = append(chunks, gjson.Parse(event.Data))
+	}
+	if len(chunks) != 6 {
+		t.Fatalf("got %d chunks, want 6: %s", len(chunks), out.String())
+	}
+	if got := chunks[1].Get("choices.0.delta.content").String(); got != "Hi" {
+		t.Errorf("text delta = %q", got)
+	}
+	if got := chunks[2].Get("choices.0.delta.tool_calls.0.function.name").String(); got != "get_weather" {
+		t.Errorf("tool name = %q", got)
+	}
+	if got := chunks[3].Get("choices.0.delta.tool_calls.0.index").Int(); got != 0 {
+		t.Errorf("tool index = %d", got)
+	}
+	if got := chunks[4].Get("choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Errorf("finish_reason = %q", got)
+	}
+	if got := chunks[5].Get("usage.total_tokens").Int(); got != 17 {
+		t.Errorf("total_tokens = %d, want 17", got)
+	}
+	if !strings.HasSuffix(out.String(), "data: [DONE]\n\n") {
+		t.Errorf("stream should end with [DONE]")
+	}
+}
+
+func equalJSONValue(got, want any) bool {
+	switch w := want.(type) {
+	case int64:
+		f, ok := got.(float64)
+		return ok && int64(f) == w
+	default:
+		return got == want
+	}
+}
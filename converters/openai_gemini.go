@@ -0,0 +1,284 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	register(consts.StyleOpenAI, consts.StyleGemini, func() Converter { return &OpenAIToGemini{} })
+}
+
+// OpenAIToGemini 使用 Gemini 原生接口提供商响应 OpenAI Chat Completions 客户端
+type OpenAIToGemini struct{}
+
+func (c *OpenAIToGemini) Request(data []byte, stream bool) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	req := geminiRequest{Contents: []geminiContent{}}
+
+	config := geminiGenerationConfig{}
+	if v := body.Get("temperature"); v.Exists() {
+		config.Temperature = lo.ToPtr(v.Float())
+	}
+	if v := body.Get("top_p"); v.Exists() {
+		config.TopP = lo.ToPtr(v.Float())
+	}
+	if v := body.Get("max_completion_tokens"); v.Exists() {
+		config.MaxOutputTokens = lo.ToPtr(v.Int())
+	} else if v := body.Get("max_tokens"); v.Exists() {
+		config.MaxOutputTokens = lo.ToPtr(v.Int())
+	}
+	if stop := body.Get("stop"); stop.IsArray() {
+		for _, s := range stop.Array() {
+			config.StopSequences = append(config.StopSequences, s.String())
+		}
+	} else if stop.String() != "" {
+		config.StopSequences = []string{stop.String()}
+	}
+	switch format := body.Get("response_format"); format.Get("type").String() {
+	case "json_object":
+		config.ResponseMimeType = "application/json"
+	case "json_schema":
+		config.ResponseMimeType = "application/json"
+		config.ResponseJSONSchema = json.RawMessage(format.Get("json_schema.schema").Raw)
+	}
+	req.GenerationConfig = &config
+
+	// tool 消息只携带 tool_call_id，Gemini 的 functionResponse 需要函数名
+	callNames := map[string]string{}
+	var systemParts []geminiPart
+	for _, message := range body.Get("messages").Array() {
+		content := message.Get("content")
+		switch role := message.Get("role").String(); role {
+		case "system", "developer":
+			if text := chatContentText(content); text != "" {
+				systemParts = append(systemParts, geminiPart{Text: text})
+			}
+		case "user":
+			req.Contents = appendGeminiContent(req.Contents, "user", openAIUserContentToGemini(content)...)
+		case "assistant":
+			var parts []geminiPart
+			if text := chatContentText(content); text != "" {
+				parts = append(parts, geminiPart{Text: text})
+			}
+			for _, call := range message.Get("tool_calls").Array() {
+				name := call.Get("function.name").String()
+				callNames[call.Get("id").String()] = name
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+					Name: name,
+					Args: toolArguments(call.Get("function.arguments").String()),
+				}})
+			}
+			req.Contents = appendGeminiContent(req.Contents, "model", parts...)
+		case "tool":
+			text := chatContentText(content)
+			response := json.RawMessage(text)
+			if !gjson.Valid(text) || !gjson.Parse(text).IsObject() {
+				response, _ = json.Marshal(map[string]string{"content": text})
+			}
+			req.Contents = appendGeminiContent(req.Contents, "user", geminiPart{FunctionResponse: &geminiFunctionResponse{
+				Name:     callNames[message.Get("tool_call_id").String()],
+				Response: response,
+			}})
+		default:
+			return nil, fmt.Errorf("unsupported message role: %s", role)
+		}
+	}
+	if len(systemParts) > 0 {
+		req.SystemInstruction = &geminiContent{Parts: systemParts}
+	}
+
+	var declarations []geminiFunctionDeclaration
+	for _, tool := range body.Get("tools").Array() {
+		if tool.Get("type").String() != "function" {
+			continue
+		}
+		declarations = append(declarations, geminiFunctionDeclaration{
+			Name:                 tool.Get("function.name").String(),
+			Description:          tool.Get("function.description").String(),
+			ParametersJSONSchema: json.RawMessage(tool.Get("function.parameters").Raw),
+		})
+	}
+	if len(declarations) > 0 {
+		req.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+		if toolChoice := body.Get("tool_choice"); toolChoice.Exists() {
+			switch toolChoice.String() {
+			case "auto":
+				req.ToolConfig = &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "AUTO"}}
+			case "none":
+				req.ToolConfig = &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "NONE"}}
+			case "required":
+				req.ToolConfig = &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY"}}
+			default:
+				if name := toolChoice.Get("function.name").String(); name != "" {
+					req.ToolConfig = &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{name}}}
+				}
+			}
+		}
+	}
+
+	return json.Marshal(req)
+}
+
+func openAIUserContentToGemini(content gjson.Result) []geminiPart {
+	if content.Type == gjson.String {
+		return []geminiPart{{Text: content.String()}}
+	}
+	var parts []geminiPart
+	content.ForEach(func(_, part gjson.Result) bool {
+		switch part.Get("type").String() {
+		case "text":
+			parts = append(parts, geminiPart{Text: part.Get("text").String()})
+		case "image_url":
+			url := part.Get("image_url.url").String()
+			if url == "" {
+				url = part.Get("image_url").String()
+			}
+			if mimeType, data, ok := parseDataURL(url); ok {
+				parts = append(parts, geminiPart{InlineData: &geminiBlob{MimeType: mimeType, Data: data}})
+			} else {
+				mimeType := lo.CoalesceOrEmpty(mime.TypeByExtension(path.Ext(url)), "image/jpeg")
+				parts = append(parts, geminiPart{FileData: &geminiFileData{MimeType: mimeType, FileURI: url}})
+			}
+		}
+		return true
+	})
+	return parts
+}
+
+// appendGeminiContent 相同角色的连续消息合并为一条
+func appendGeminiContent(contents []geminiContent, role string, parts ...geminiPart) []geminiContent {
+	if len(parts) == 0 {
+		return contents
+	}
+	if n := len(contents); n > 0 && contents[n-1].Role == role {
+		contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+		return contents
+	}
+	return append(contents, geminiContent{Role: role, Parts: parts})
+}
+
+// geminiCandidateToChat 将 Gemini candidate 的 parts 转换为 OpenAI 消息
+func geminiCandidateToChat(candidate gjson.Result, toolIndex int) (chatMessage, int) {
+	var message chatMessage
+	var text strings.Builder
+	for _, part := range candidate.Get("content.parts").Array() {
+		if call := geminiGet(part, "functionCall", "function_call"); call.Exists() {
+			id := call.Get("id").String()
+			if id == "" {
+				id = fmt.Sprintf("call_%d", toolIndex)
+			}
+			message.ToolCalls = append(message.ToolCalls, chatToolCall{
+				Index:    lo.ToPtr(toolIndex),
+				ID:       id,
+				Type:     "function",
+				Function: chatFunctionCall{Name: call.Get("name").String(), Arguments: string(toolArguments(call.Get("args").Raw))},
+			})
+			toolIndex++
+			continue
+		}
+		if part.Get("thought").Bool() {
+			message.ReasoningContent += part.Get("text").String()
+			continue
+		}
+		text.WriteString(part.Get("text").String())
+	}
+	if text.Len() > 0 {
+		message.Content = lo.ToPtr(text.String())
+	}
+	return message, toolIndex
+}
+
+func (c *OpenAIToGemini) Response(data []byte) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	candidate := body.Get("candidates.0")
+	message, _ := geminiCandidateToChat(candidate, 0)
+	message.Role = "assistant"
+	for i := range message.ToolCalls {
+		message.ToolCalls[i].Index = nil
+	}
+	if message.Content == nil && len(message.ToolCalls) == 0 {
+		message.Content = lo.ToPtr("")
+	}
+	reason := geminiFinishReasonToOpenAI(geminiGet(candidate, "finishReason", "finish_reason").String(), len(message.ToolCalls) > 0)
+	return json.Marshal(chatCompletion{
+		ID:      lo.CoalesceOrEmpty(body.Get("responseId").String(), "chatcmpl-gemini"),
+		Object:  "chat.completion",
+		Created: unixNow(),
+		Model:   body.Get("modelVersion").String(),
+		Choices: []chatChoice{{
+			Index:        0,
+			Message:      &message,
+			FinishReason: lo.ToPtr(reason),
+		}},
+		Usage: chatUsageFromGemini(geminiGet(body, "usageMetadata", "usage_metadata")),
+	})
+}
+
+func (c *OpenAIToGemini) Stream(r io.Reader, w io.Writer) error {
+	var (
+		id, model string
+		started   bool
+		toolIndex int
+		usage     *chatUsage
+		created   = unixNow()
+	)
+	emit := func(delta chatMessage, finishReason *string) error {
+		chunk := newChatChunk(id, model, created)
+		chunk.Choices = []chatChoice{{Index: 0, Delta: &delta, FinishReason: finishReason}}
+		return writeChatChunk(w, chunk)
+	}
+
+	for event, err := range scanSSE(r) {
+		if err != nil {
+			return err
+		}
+		chunk := gjson.Parse(event.Data)
+		if errMsg := chunk.Get("error"); errMsg.Exists() {
+			return writeSSE(w, "", []byte(event.Data))
+		}
+		if !started {
+			started = true
+			id = lo.CoalesceOrEmpty(chunk.Get("responseId").String(), "chatcmpl-gemini")
+			model = chunk.Get("modelVersion").String()
+			if err := emit(chatMessage{Role: "assistant", Content: lo.ToPtr("")}, nil); err != nil {
+				return err
+			}
+		}
+		// Gemini 每个分片的 usageMetadata 为累计值
+		if u := geminiGet(chunk, "usageMetadata", "usage_metadata"); u.Exists() {
+			usage = chatUsageFromGemini(u)
+		}
+
+		candidate := chunk.Get("candidates.0")
+		var delta chatMessage
+		delta, toolIndex = geminiCandidateToChat(candidate, toolIndex)
+		if delta.Content != nil || delta.ReasoningContent != "" || len(delta.ToolCalls) > 0 {
+			if err := emit(delta, nil); err != nil {
+				return err
+			}
+		}
+		if reason := geminiGet(candidate, "finishReason", "finish_reason").String(); reason != "" {
+			if err := emit(chatMessage{}, lo.ToPtr(geminiFinishReasonToOpenAI(reason, toolIndex > 0))); err != nil {
+				return err
+			}
+		}
+	}
+
+	if usage != nil {
+		chunk := newChatChunk(id, model, created)
+		chunk.Usage = usage
+		if err := writeChatChunk(w, chunk); err != nil {
+			return err
+		}
+	}
+	return writeChatDone(w)
+}
@@ -0,0 +1,185 @@
+package converters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIToGeminiRequest(t *testing.T) {
+	in := `{
+		"model": "gemini-2.5-flash",
+		"max_tokens": 512,
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": [
+				{"type": "text", "text": "Describe"},
+				{"type": "image_url", "image_url": {"url": "data:image/jpeg;base64,aGVsbG8="}}
+			]},
+			{"role": "assistant", "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "lookup", "arguments": "{\"q\":\"cat\"}"}}
+			]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "a small animal"}
+		],
+		"tools": [{"type": "function", "function": {"name": "lookup", "parameters": {"type": "object"}}}],
+		"tool_choice": {"type": "function", "function": {"name": "lookup"}},
+		"response_format": {"type": "json_schema", "json_schema": {"name": "r", "schema": {"type": "object"}}}
+	}`
+
+	out, err := (&OpenAIToGemini{}).Request([]byte(in), false)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+
+	checks := map[string]string{
+		"systemInstruction.parts.0.text":                          "Be brief.",
+		"contents.0.role":                                         "user",
+		"contents.0.parts.1.inlineData.mimeType":                  "image/jpeg",
+		"contents.1.role":                                         "model",
+		"contents.1.parts.0.functionCall.args.q":                  "cat",
+		"contents.2.parts.0.functionResponse.name":                "lookup",
+		"contents.2.parts.0.functionResponse.response.content":    "a small animal",
+		"tools.0.functionDeclarations.0.name":                     "lookup",
+		"toolConfig.functionCallingConfig.mode":                   "ANY",
+		"toolConfig.functionCallingConfig.allowedFunctionNames.0": "lookup",
+		"generationConfig.maxOutputTokens":                        "512",
+		"generationConfig.responseMimeType":                       "application/json",
+		"generationConfig.responseJsonSchema.type":                "object",
+	}
+	for path, want := range checks {
+		if got := res.Get(path).String(); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestOpenAIToGeminiStream(t *testing.T) {
+	in := strings.Join([]string{
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}],"usageMetadata":{"promptTokenCount":5},"modelVersion":"gemini-2.5-flash","responseId":"r1"}`,
+		``,
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"lookup","args":{"q":"cat"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":4,"thoughtsTokenCount":2,"totalTokenCount":11}}`,
+		``,
+	}, "\n")
+
+	var out strings.Builder
+	if err := (&OpenAIToGemini{}).Stream(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	var chunks []gjson.Result
+	for event, err := range scanSSE(strings.NewReader(out.String())) {
+		if err != nil {
+			t.Fatalf("scanSSE() unexpected error: %v", err)
+		}
+		if event.Data != "[DONE]" {
+			chunks = append(chunks, gjson.Parse(event.Data))
+		}
+	}
+	if len(chunks) != 5 {
+		t.Fatalf("got %d chunks, want 5: %s", len(chunks), out.String())
+	}
+	if got := chunks[1].Get("choices.0.delta.content").String(); got != "Hel" {
+		t.Errorf("content = %q", got)
+	}
+	if got := chunks[2].Get("choices.0.delta.tool_calls.0.function.arguments").String(); got != `{"q":"cat"}` {
+		t.Errorf("arguments = %q", got)
+	}
+	if got := chunks[3].Get("choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Errorf("finish_reason = %q", got)
+	}
+	if got := chunks[4].Get("usage.completion_tokens").Int(); got != 6 {
+		t.Errorf("completion_tokens = %d, want 6", got)
+	}
+}
+
+func TestGeminiToOpenAIRequest(t *testing.T) {
+	in := `{
+		"systemInstruction": {"parts": [{"text": "Be brief."}]},
+		"contents": [
+			{"role": "user", "parts": [{"text": "Weather?"}]},
+			{"role": "model", "parts": [{"functionCall": {"name": "get_weather", "args": {"city": "Nanjing"}}}]},
+			{"role": "user", "parts": [{"functionResponse": {"name": "get_weather", "response": {"temp": 18}}}]}
+		],
+		"tools": [{"functionDeclarations": [{"name": "get_weather", "parameters": {"type": "OBJECT", "properties": {"city": {"type": "STRING"}}}}]}],
+		"toolConfig": {"functionCallingConfig": {"mode": "AUTO"}},
+		"generationConfig": {"maxOutputTokens": 256, "responseMimeType": "application/json"}
+	}`
+
+	out, err := (&GeminiToOpenAI{}).Request([]byte(in), true)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+
+	checks := map[string]string{
+		"messages.0.content":                               "Be brief.",
+		"messages.1.content.0.text":                        "Weather?",
+		"messages.2.tool_calls.0.id":                       "call_0",
+		"messages.3.tool_call_id":                          "call_0",
+		"messages.3.content":                               `{"temp": 18}`,
+		"tools.0.function.parameters.type":                 "object",
+		"tools.0.function.parameters.properties.city.type": "string",
+		"tool_choice":                                      "auto",
+		"max_tokens":                                       "256",
+		"response_format.type":                             "json_object",
+		"stream_options.include_usage":                     "true",
+	}
+	for path, want := range checks {
+		if got := res.Get(path).String(); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestGeminiToOpenAIStream(t *testing.T) {
+	in := strings.Join([]string{
+		`data: {"id":"c1","model":"gpt-4.1","choices":[{"index":0,"delta":{"content":"Hi"}}]}`,
+		``,
+		`data: {"id":"c1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"f","arguments":"{\"a\":"}}]}}]}`,
+		``,
+		`data: {"id":"c1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"1}"}}]},"finish_reason":"tool_calls"}]}`,
+		``,
+		`data: {"id":"c1","model":"gpt-4.1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	var out strings.Builder
+	if err := (&GeminiToOpenAI{}).Stream(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	var chunks []gjson.Result
+	for event, err := range scanSSE(strings.NewReader(out.String())) {
+		if err != nil {
+			t.Fatalf("scanSSE() unexpected error: %v", err)
+		}
+		chunks = append(chunks, gjson.Parse(event.Data))
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2: %s", len(chunks), out.String())
+	}
+	last := chunks[1]
+	if got := last.Get("candidates.0.content.parts.0.functionCall.args.a").Int(); got != 1 {
+		t.Errorf("functionCall args = %s", last.Get("candidates.0.content.parts.0.functionCall.args").Raw)
+	}
+	if got := last.Get("usageMetadata.totalTokenCount").Int(); got != 7 {
+		t.Errorf("totalTokenCount = %d, want 7", got)
+	}
+	if got := last.Get("candidates.0.finishReason").String(); got != "STOP" {
+		t.Errorf("finishReason = %q", got)
+	}
+}
+
+func TestGeminiToOpenAIStreamInvalidToolIndex(t *testing.T) {
+	for _, index := range []string{"-1", "100000000"} {
+		in := `data: {"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":` + index + `,"id":"call_1","function":{"name":"f","arguments":"{}"}}]}}]}` + "\n\n"
+		var out strings.Builder
+		if err := (&GeminiToOpenAI{}).Stream(strings.NewReader(in), &out); err == nil {
+			t.Errorf("Stream() with tool call index %s should fail", index)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package converters
+
+import (
+	"github.com/atopos31/llmio/pkg/token"
+)
+
+// OpenAI Responses 协议的最小结构定义
+
+type responseObject struct {
+	ID                 string              `json:"id"`
+	Object             string              `json:"object"`
+	CreatedAt          int64               `json:"created_at"`
+	Status             string              `json:"status"`
+	Model              string              `json:"model"`
+	Output             []responseItem      `json:"output"`
+	Usage              *responseUsage      `json:"usage,omitempty"`
+	IncompleteDetails  *responseIncomplete `json:"incomplete_details"`
+	PreviousResponseID string              `json:"previous_response_id,omitempty"`
+	Instructions       string              `json:"instructions,omitempty"`
+	Error              *responseError      `json:"error"`
+}
+
+type responseIncomplete struct {
+	Reason string `json:"reason"`
+}
+
+type responseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type responseItem struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Status string `json:"status,omitempty"`
+	// message
+	Role    string            `json:"role,omitempty"`
+	Content []responseContent `json:"content,omitempty"`
+	// function_call
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	// reasoning
+	Summary []responseContent `json:"summary,omitempty"`
+}
+
+type responseContent struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	Annotations []any  `json:"annotations,omitempty"`
+}
+
+type responseUsage struct {
+	InputTokens         int64                      `json:"input_tokens"`
+	OutputTokens        int64                      `json:"output_tokens"`
+	TotalTokens         int64                      `json:"total_tokens"`
+	InputTokensDetails  responseInputTokenDetails  `json:"input_tokens_details"`
+	OutputTokensDetails responseOutputTokenDetails `json:"output_tokens_details"`
+}
+
+type responseInputTokenDetails struct {
+	CachedTokens int64 `json:"cached_tokens"`
+}
+
+type responseOutputTokenDetails struct {
+	ReasoningTokens int64 `json:"reasoning_tokens"`
+}
+
+func newResponseID(prefix string) string {
+	id, err := token.GenerateRandomChars(24)
+	if err != nil {
+		return prefix
+	}
+	return prefix + "_" + id
+}
+
+// Conversation 使用 chat 提供商模拟 Responses 接口时的一轮会话，由调用方负责加载与保存
+type Conversation struct {
+	ResponseID         string
+	PreviousResponseID string
+	Messages           []byte // 本轮输入与输出的 chat messages JSON
+}
+
+// Conversational 需要 previous_response_id 会话上下文的转换器
+type Conversational interface {
+	// SetHistory 设置 previous_response_id 链上的历史 chat messages JSON
+	SetHistory(messages []byte) error
+	// Conversation 返回需要保存的本轮会话，响应未完成或 store=false 时 ok 为 false
+	Conversation() (Conversation, bool)
+}
@@ -0,0 +1,501 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	register(consts.StyleOpenAIRes, consts.StyleOpenAI, func() Converter { return &OpenAIResToOpenAI{} })
+}
+
+// OpenAIResToOpenAI 使用 Chat Completions 提供商模拟 Responses 接口。
+// previous_response_id 的历史消息由调用方通过 SetHistory 注入。
+type OpenAIResToOpenAI struct {
+	model        string
+	instructions string
+	previousID   string
+	store        bool
+	history      []chatRequestMessage
+	// 本轮输入的 chat messages，响应完成后与输出一同保存
+	turn         []chatRequestMessage
+	conversation *Conversation
+}
+
+func (c *OpenAIResToOpenAI) SetHistory(messages []byte) error {
+	c.history = nil
+	if messages == nil {
+		return nil
+	}
+	return json.Unmarshal(messages, &c.history)
+}
+
+func (c *OpenAIResToOpenAI) Conversation() (Conversation, bool) {
+	if c.conversation == nil {
+		return Conversation{}, false
+	}
+	return *c.conversation, true
+}
+
+func (c *OpenAIResToOpenAI) Request(data []byte, stream bool) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	c.model = body.Get("model").String()
+	c.instructions = body.Get("instructions").String()
+	c.previousID = body.Get("previous_response_id").String()
+	c.store = !body.Get("store").Exists() || body.Get("store").Bool()
+
+	req := chatRequest{
+		Model:           c.model,
+		Stream:          stream,
+		User:            body.Get("user").String(),
+		ReasoningEffort: body.Get("reasoning.effort").String(),
+	}
+	if stream {
+		req.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+	}
+	if v := body.Get("max_output_tokens"); v.Exists() {
+		req.MaxTokens = lo.ToPtr(v.Int())
+	}
+	if v := body.Get("temperature"); v.Exists() {
+		req.Temperature = lo.ToPtr(v.Float())
+	}
+	if v := body.Get("top_p"); v.Exists() {
+		req.TopP = lo.ToPtr(v.Float())
+	}
+	if v := body.Get("parallel_tool_calls"); v.Exists() {
+		req.ParallelToolCalls = lo.ToPtr(v.Bool())
+	}
+
+	// instructions 不会随 previous_response_id 延续，每次请求单独携带
+	if c.instructions != "" {
+		req.Messages = append(req.Messages, chatRequestMessage{Role: "system", Content: c.instructions})
+	}
+	if c.previousID != "" {
+		if c.history == nil {
+			return nil, fmt.Errorf("previous response not found: %s", c.previousID)
+		}
+		req.Messages = append(req.Messages, c.history...)
+	}
+	c.turn = responsesInputToChat(body.Get("input"))
+	req.Messages = append(req.Messages, c.turn...)
+
+	for _, tool := range body.Get("tools").Array() {
+		if tool.Get("type").String() != "function" {
+			continue
+		}
+		req.Tools = append(req.Tools, chatTool{
+			Type: "function",
+			Function: chatFunction{
+				Name:        tool.Get("name").String(),
+				Description: tool.Get("description").String(),
+				Parameters:  json.RawMessage(tool.Get("parameters").Raw),
+			},
+		})
+	}
+	if toolChoice := body.Get("tool_choice"); toolChoice.Exists() && len(req.Tools) > 0 {
+		switch toolChoice.String() {
+		case "auto", "none", "required":
+			req.ToolChoice = toolChoice.String()
+		default:
+			if name := toolChoice.Get("name").String(); name != "" {
+				req.ToolChoice = newChatNamedToolChoice(name)
+			}
+		}
+	}
+	switch format := body.Get("text.format"); format.Get("type").String() {
+	case "json_object":
+		req.ResponseFormat = map[string]string{"type": "json_object"}
+	case "json_schema":
+		req.ResponseFormat = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   lo.CoalesceOrEmpty(format.Get("name").String(), "response"),
+				"schema": json.RawMessage(format.Get("schema").Raw),
+				"strict": format.Get("strict").Bool(),
+			},
+		}
+	}
+
+	return json.Marshal(req)
+}
+
+// responsesInputToChat 将 Responses input 转换为 chat messages
+func responsesInputToChat(input gjson.Result) []chatRequestMessage {
+	if input.Type == gjson.String {
+		return []chatRequestMessage{{Role: "user", Content: input.String()}}
+	}
+	var messages []chatRequestMessage
+	for _, item := range input.Array() {
+		switch item.Get("type").String() {
+		case "message", "":
+			role := item.Get("role").String()
+			content := item.Get("content")
+			switch role {
+			case "assistant":
+				messages = append(messages, chatRequestMessage{Role: "assistant", Content: chatContentText(content)})
+			case "system", "developer":
+				messages = append(messages, chatRequestMessage{Role: "system", Content: chatContentText(content)})
+			default:
+				messages = append(messages, chatRequestMessage{Role: "user", Content: responsesUserContent(content)})
+			}
+		case "function_call":
+			call := chatToolCall{
+				ID:       item.Get("call_id").String(),
+				Type:     "function",
+				Function: chatFunctionCall{Name: item.Get("name").String(), Arguments: item.Get("arguments").String()},
+			}
+			if n := len(messages); n > 0 && messages[n-1].Role == "assistant" {
+				messages[n-1].ToolCalls = append(messages[n-1].ToolCalls, call)
+				continue
+			}
+			messages = append(messages, chatRequestMessage{Role: "assistant", ToolCalls: []chatToolCall{call}})
+		case "function_call_output":
+			output := item.Get("output")
+			messages = append(messages, chatRequestMessage{
+				Role:       "tool",
+				ToolCallID: item.Get("call_id").String(),
+				Content:    chatContentText(output),
+			})
+		}
+	}
+	return messages
+}
+
+func responsesUserContent(content gjson.Result) any {
+	if content.Type == gjson.String {
+		return content.String()
+	}
+	var parts []chatContentPart
+	content.ForEach(func(_, part gjson.Result) bool {
+		switch part.Get("type").String() {
+		case "input_text", "output_text", "text":
+			parts = append(parts, chatContentPart{Type: "text", Text: part.Get("text").String()})
+		case "input_image":
+			if url := part.Get("image_url").String(); url != "" {
+				parts = append(parts, chatContentPart{Type: "image_url", ImageURL: &chatImageURL{URL: url}})
+			}
+		}
+		return true
+	})
+	return parts
+}
+
+func responseUsageFromChat(usage gjson.Result) *responseUsage {
+	if !usage.IsObject() {
+		return nil
+	}
+	return &responseUsage{
+		InputTokens:         usage.Get("prompt_tokens").Int(),
+		OutputTokens:        usage.Get("completion_tokens").Int(),
+		TotalTokens:         usage.Get("total_tokens").Int(),
+		InputTokensDetails:  responseInputTokenDetails{CachedTokens: usage.Get("prompt_tokens_details.cached_tokens").Int()},
+		OutputTokensDetails: responseOutputTokenDetails{ReasoningTokens: usage.Get("completion_tokens_details.reasoning_tokens").Int()},
+	}
+}
+
+func (c *OpenAIResToOpenAI) newResponse() responseObject {
+	return responseObject{
+		ID:                 newResponseID("resp"),
+		Object:             "response",
+		CreatedAt:          unixNow(),
+		Status:             "in_progress",
+		Model:              c.model,
+		Output:             []responseItem{},
+		PreviousResponseID: c.previousID,
+		Instructions:       c.instructions,
+	}
+}
+
+// finish 根据 finish_reason 设置最终状态并记录本轮会话
+func (c *OpenAIResToOpenAI) finish(res *responseObject, finishReason string) {
+	res.Status = "completed"
+	if finishReason == "length" {
+		res.Status = "incomplete"
+		res.IncompleteDetails = &responseIncomplete{Reason: "max_output_tokens"}
+	}
+	if !c.store {
+		return
+	}
+	assistant := chatRequestMessage{Role: "assistant"}
+	var text strings.Builder
+	for _, item := range res.Output {
+		switch item.Type {
+		case "message":
+			for _, content := range item.Content {
+				text.WriteString(content.Text)
+			}
+		case "function_call":
+			assistant.ToolCalls = append(assistant.ToolCalls, chatToolCall{
+				ID:       item.CallID,
+				Type:     "function",
+				Function: chatFunctionCall{Name: item.Name, Arguments: item.Arguments},
+			})
+		}
+	}
+	if text.Len() > 0 || len(assistant.ToolCalls) == 0 {
+		assistant.Content = text.String()
+	}
+	messages, err := json.Marshal(append(c.turn, assistant))
+	if err != nil {
+		slog.Error("marshal response conversation error", "error", err)
+		return
+	}
+	c.conversation = &Conversation{ResponseID: res.ID, PreviousResponseID: c.previousID, Messages: messages}
+}
+
+func (c *OpenAIResToOpenAI) Response(data []byte) ([]byte, error) {
+	body := gjson.ParseBytes(data)
+	if errMsg := body.Get("error"); errMsg.Exists() {
+		return nil, fmt.Errorf("provider error: %s", errMsg.Raw)
+	}
+	message := body.Get("choices.0.message")
+	res := c.newResponse()
+	res.Model = lo.CoalesceOrEmpty(body.Get("model").String(), c.model)
+	if reasoning := chatReasoning(message); reasoning != "" {
+		res.Output = append(res.Output, responseItem{
+			Type:    "reasoning",
+			ID:      newResponseID("rs"),
+			Summary: []responseContent{{Type: "summary_text", Text: reasoning}},
+		})
+	}
+	if text := chatContentText(message.Get("content")); text != "" {
+		res.Output = append(res.Output, responseItem{
+			Type:    "message",
+			ID:      newResponseID("msg"),
+			Status:  "completed",
+			Role:    "assistant",
+			Content: []responseContent{{Type: "output_text", Text: text, Annotations: []any{}}},
+		})
+	}
+	for _, call := range message.Get("tool_calls").Array() {
+		res.Output = append(res.Output, responseItem{
+			Type:      "function_call",
+			ID:        newResponseID("fc"),
+			Status:    "completed",
+			CallID:    call.Get("id").String(),
+			Name:      call.Get("function.name").String(),
+			Arguments: call.Get("function.arguments").String(),
+		})
+	}
+	res.Usage = responseUsageFromChat(body.Get("usage"))
+	c.finish(&res, body.Get("choices.0.finish_reason").String())
+	return json.Marshal(res)
+}
+
+// responseStreamWriter 按 Responses 规范输出 response.* 事件
+type responseStreamWriter struct {
+	w        io.Writer
+	sequence int
+	res      responseObject
+	// 当前打开的 reasoning / message 项在 output 中的下标，-1 表示无
+	current int
+	// chat tool_calls index -> output 下标
+	tools map[int64]int
+}
+
+func (s *responseStreamWriter) event(eventType string, payload map[string]any) error {
+	payload["type"] = eventType
+	payload["sequence_number"] = s.sequence
+	s.sequence++
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return writeSSE(s.w, eventType, data)
+}
+
+func (s *responseStreamWriter) addItem(item responseItem) (int, error) {
+	index := len(s.res.Output)
+	s.res.Output = append(s.res.Output, item)
+	return index, s.event("response.output_item.added", map[string]any{"output_index": index, "item": item})
+}
+
+// open 打开 reasoning 或 message 项，已打开同类型项时直接复用
+func (s *responseStreamWriter) open(itemType string) (int, error) {
+	if s.current >= 0 && s.res.Output[s.current].Type == itemType {
+		return s.current, nil
+	}
+	if err := s.closeCurrent(); err != nil {
+		return 0, err
+	}
+	var err error
+	switch itemType {
+	case "reasoning":
+		s.current, err = s.addItem(responseItem{Type: "reasoning", ID: newResponseID("rs"), Summary: []responseContent{}})
+		if err != nil {
+			return 0, err
+		}
+		s.res.Output[s.current].Summary = []responseContent{{Type: "summary_text"}}
+		err = s.event("response.reasoning_summary_part.added", map[string]any{
+			"item_id": s.res.Output[s.current].ID, "output_index": s.current, "summary_index": 0,
+			"part": responseContent{Type: "summary_text"},
+		})
+	case "message":
+		s.current, err = s.addItem(responseItem{Type: "message", ID: newResponseID("msg"), Status: "in_progress", Role: "assistant", Content: []responseContent{}})
+		if err != nil {
+			return 0, err
+		}
+		s.res.Output[s.current].Content = []responseContent{{Type: "output_text", Annotations: []any{}}}
+		err = s.event("response.content_part.added", map[string]any{
+			"item_id": s.res.Output[s.current].ID, "output_index": s.current, "content_index": 0,
+			"part": responseContent{Type: "output_text", Annotations: []any{}},
+		})
+	}
+	return s.current, err
+}
+
+func (s *responseStreamWriter) closeCurrent() error {
+	if s.current < 0 {
+		return nil
+	}
+	index := s.current
+	s.current = -1
+	item := &s.res.Output[index]
+	switch item.Type {
+	case "reasoning":
+		part := item.Summary[0]
+		if err := s.event("response.reasoning_summary_text.done", map[string]any{"item_id": item.ID, "output_index": index, "summary_index": 0, "text": part.Text}); err != nil {
+			return err
+		}
+		if err := s.event("response.reasoning_summary_part.done", map[string]any{"item_id": item.ID, "output_index": index, "summary_index": 0, "part": part}); err != nil {
+			return err
+		}
+	case "message":
+		part := item.Content[0]
+		if err := s.event("response.output_text.done", map[string]any{"item_id": item.ID, "output_index": index, "content_index": 0, "text": part.Text}); err != nil {
+			return err
+		}
+		if err := s.event("response.content_part.done", map[string]any{"item_id": item.ID, "output_index": index, "content_index": 0, "part": part}); err != nil {
+			return err
+		}
+		item.Status = "completed"
+	}
+	return s.event("response.output_item.done", map[string]any{"output_index": index, "item": *item})
+}
+
+func (s *responseStreamWriter) closeTools() error {
+	// 按 output 下标顺序结束，保证事件顺序稳定
+	for _, index := range slices.Sorted(maps.Values(s.tools)) {
+		item := &s.res.Output[index]
+		item.Status = "completed"
+		if err := s.event("response.function_call_arguments.done", map[string]any{"item_id": item.ID, "output_index": index, "arguments": item.Arguments}); err != nil {
+			return err
+		}
+		if err := s.event("response.output_item.done", map[string]any{"output_index": index, "item": *item}); err != nil {
+			return err
+		}
+	}
+	s.tools = map[int64]int{}
+	return nil
+}
+
+func (c *OpenAIResToOpenAI) Stream(r io.Reader, w io.Writer) error {
+	stream := &responseStreamWriter{w: w, res: c.newResponse(), current: -1, tools: map[int64]int{}}
+	if err := stream.event("response.created", map[string]any{"response": stream.res}); err != nil {
+		return err
+	}
+	if err := stream.event("response.in_progress", map[string]any{"response": stream.res}); err != nil {
+		return err
+	}
+
+	var finishReason string
+	for event, err := range scanSSE(r) {
+		if err != nil {
+			return err
+		}
+		if event.Data == "[DONE]" {
+			break
+		}
+		chunk := gjson.Parse(event.Data)
+		if errMsg := chunk.Get("error"); errMsg.Exists() {
+			stream.res.Status = "failed"
+			stream.res.Error = &responseError{Code: "server_error", Message: lo.CoalesceOrEmpty(errMsg.Get("message").String(), errMsg.String())}
+			return stream.event("response.failed", map[string]any{"response": stream.res})
+		}
+		if model := chunk.Get("model").String(); model != "" {
+			stream.res.Model = model
+		}
+		if u := chunk.Get("usage"); u.IsObject() && u.Get("total_tokens").Int() != 0 {
+			stream.res.Usage = responseUsageFromChat(u)
+		}
+
+		choice := chunk.Get("choices.0")
+		delta := choice.Get("delta")
+		if reasoning := chatReasoning(delta); reasoning != "" {
+			index, err := stream.open("reasoning")
+			if err != nil {
+				return err
+			}
+			stream.res.Output[index].Summary[0].Text += reasoning
+			if err := stream.event("response.reasoning_summary_text.delta", map[string]any{
+				"item_id": stream.res.Output[index].ID, "output_index": index, "summary_index": 0, "delta": reasoning,
+			}); err != nil {
+				return err
+			}
+		}
+		if text := delta.Get("content").String(); text != "" {
+			index, err := stream.open("message")
+			if err != nil {
+				return err
+			}
+			stream.res.Output[index].Content[0].Text += text
+			if err := stream.event("response.output_text.delta", map[string]any{
+				"item_id": stream.res.Output[index].ID, "output_index": index, "content_index": 0, "delta": text,
+			}); err != nil {
+				return err
+			}
+		}
+		for _, call := range delta.Get("tool_calls").Array() {
+			toolIndex := call.Get("index").Int()
+			index, ok := stream.tools[toolIndex]
+			if !ok {
+				if err := stream.closeCurrent(); err != nil {
+					return err
+				}
+				index, err = stream.addItem(responseItem{
+					Type:   "function_call",
+					ID:     newResponseID("fc"),
+					Status: "in_progress",
+					CallID: call.Get("id").String(),
+					Name:   call.Get("function.name").String(),
+				})
+				if err != nil {
+					return err
+				}
+				stream.tools[toolIndex] = index
+			}
+			if args := call.Get("function.arguments").String(); args != "" {
+				stream.res.Output[index].Arguments += args
+				if err := stream.event("response.function_call_arguments.delta", map[string]any{
+					"item_id": stream.res.Output[index].ID, "output_index": index, "delta": args,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		if reason := choice.Get("finish_reason").String(); reason != "" {
+			finishReason = reason
+		}
+	}
+
+	if err := stream.closeCurrent(); err != nil {
+		return err
+	}
+	if err := stream.closeTools(); err != nil {
+		return err
+	}
+	c.finish(&stream.res, finishReason)
+	if stream.res.Status == "incomplete" {
+		return stream.event("response.incomplete", map[string]any{"response": stream.res})
+	}
+	return stream.event("response.completed", map[string]any{"response": stream.res})
+}
@@ -0,0 +1,168 @@
+package converters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIResToOpenAIRequest(t *testing.T) {
+	in := `{
+		"model": "gpt-4.1",
+		"instructions": "Be brief.",
+		"max_output_tokens": 128,
+		"input": [
+			{"role": "user", "content": [
+				{"type": "input_text", "text": "Weather?"},
+				{"type": "input_image", "image_url": "https://example.com/a.png"}
+			]},
+			{"type": "function_call", "call_id": "call_1", "name": "get_weather", "arguments": "{\"city\":\"Nanjing\"}"},
+			{"type": "function_call_output", "call_id": "call_1", "output": "18C"}
+		],
+		"tools": [{"type": "function", "name": "get_weather", "parameters": {"type": "object"}}, {"type": "web_search"}],
+		"tool_choice": {"type": "function", "name": "get_weather"},
+		"text": {"format": {"type": "json_schema", "name": "w", "schema": {"type": "object"}}}
+	}`
+
+	out, err := (&OpenAIResToOpenAI{}).Request([]byte(in), true)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+
+	checks := map[string]string{
+		"messages.0.role":                    "system",
+		"messages.0.content":                 "Be brief.",
+		"messages.1.content.0.text":          "Weather?",
+		"messages.1.content.1.image_url.url": "https://example.com/a.png",
+		"messages.2.tool_calls.0.id":         "call_1",
+		"messages.3.role":                    "tool",
+		"messages.3.content":                 "18C",
+		"tools.#":                            "1",
+		"tool_choice.function.name":          "get_weather",
+		"response_format.json_schema.name":   "w",
+		"max_tokens":                         "128",
+		"stream_options.include_usage":       "true",
+	}
+	for path, want := range checks {
+		if got := res.Get(path).String(); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	if _, err := (&OpenAIResToOpenAI{}).Request([]byte(`{"model":"m","input":"hi","previous_response_id":"resp_missing"}`), false); err == nil {
+		t.Error("Request() with unknown previous_response_id should fail")
+	}
+}
+
+func TestOpenAIResToOpenAIPreviousResponse(t *testing.T) {
+
+	first := &OpenAIResToOpenAI{}
+	if _, err := first.Request([]byte(`{"model":"gpt-4.1","input":"My name is Tom."}`), false); err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	out, err := first.Response([]byte(`{"id":"c1","model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hi Tom."},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`))
+	if err != nil {
+		t.Fatalf("Response() unexpected error: %v", err)
+	}
+	res := gjson.ParseBytes(out)
+	if got := res.Get("output.0.content.0.text").String(); got != "Hi Tom." {
+		t.Errorf("output text = %q", got)
+	}
+	if got := res.Get("usage.total_tokens").Int(); got != 8 {
+		t.Errorf("usage.total_tokens = %d, want 8", got)
+	}
+
+	turn, ok := first.Conversation()
+	if !ok {
+		t.Fatal("Conversation() should return the finished turn")
+	}
+	if turn.ResponseID != res.Get("id").String() {
+		t.Errorf("turn.ResponseID = %q, want %q", turn.ResponseID, res.Get("id").String())
+	}
+
+	second := &OpenAIResToOpenAI{}
+	if err := second.SetHistory(turn.Messages); err != nil {
+		t.Fatalf("SetHistory() unexpected error: %v", err)
+	}
+	req, err := second.Request([]byte(`{"model":"gpt-4.1","input":"What is my name?","previous_response_id":"`+res.Get("id").String()+`"}`), false)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	messages := gjson.GetBytes(req, "messages")
+	if got := messages.Get("#").Int(); got != 3 {
+		t.Fatalf("got %d messages, want 3: %s", got, messages.Raw)
+	}
+	if got := messages.Get("1.content").String(); got != "Hi Tom." {
+		t.Errorf("history assistant content = %q", got)
+	}
+}
+
+func TestOpenAIResToOpenAIStream(t *testing.T) {
+	in := strings.Join([]string{
+		`data: {"id":"c1","model":"gpt-4.1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		``,
+		`data: {"id":"c1","model":"gpt-4.1","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		``,
+		`data: {"id":"c1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"f","arguments":"{\"a\":"}}]}}]}`,
+		``,
+		`data: {"id":"c1","model":"gpt-4.1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"1}"}}]},"finish_reason":"tool_calls"}]}`,
+		``,
+		`data: {"id":"c1","model":"gpt-4.1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	converter := &OpenAIResToOpenAI{}
+	if _, err := converter.Request([]byte(`{"model":"gpt-4.1","input":"hi","stream":true}`), true); err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	var out strings.Builder
+	if err := converter.Stream(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	var events []string
+	var last gjson.Result
+	for event, err := range scanSSE(strings.NewReader(out.String())) {
+		if err != nil {
+			t.Fatalf("scanSSE() unexpected error: %v", err)
+		}
+		events = append(events, event.Event)
+		last = gjson.Parse(event.Data)
+	}
+	want := []string{
+		"response.created",
+		"response.in_progress",
+		"response.output_item.added",
+		"response.content_part.added",
+		"response.output_text.delta",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.content_part.done",
+		"response.output_item.done",
+		"response.output_item.added",
+		"response.function_call_arguments.delta",
+		"response.function_call_arguments.delta",
+		"response.function_call_arguments.done",
+		"response.output_item.done",
+		"response.completed",
+	}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	if got := last.Get("response.output.0.content.0.text").String(); got != "Hello" {
+		t.Errorf("output text = %q", got)
+	}
+	if got := last.Get("response.output.1.arguments").String(); got != `{"a":1}` {
+		t.Errorf("arguments = %q", got)
+	}
+	if got := last.Get("response.usage.total_tokens").Int(); got != 7 {
+		t.Errorf("usage.total_tokens = %d, want 7", got)
+	}
+	if got := last.Get("sequence_number").Int(); got != int64(len(want)-1) {
+		t.Errorf("sequence_number = %d", got)
+	}
+}
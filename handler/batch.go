@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/atopos31/llmio/common"
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/models"
+	"github.com/atopos31/llmio/service"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// OpenAI Files / Batches 接口的响应结构
+
+type FileObject struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int    `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+}
+
+type BatchObject struct {
+	ID               string             `json:"id"`
+	Object           string             `json:"object"`
+	Endpoint         string             `json:"endpoint"`
+	Errors           *BatchErrors       `json:"errors"`
+	InputFileID      string             `json:"input_file_id"`
+	CompletionWindow string             `json:"completion_window"`
+	Status           string             `json:"status"`
+	OutputFileID     *string            `json:"output_file_id"`
+	ErrorFileID      *string            `json:"error_file_id"`
+	CreatedAt        int64              `json:"created_at"`
+	InProgressAt     *int64             `json:"in_progress_at"`
+	ExpiresAt        int64              `json:"expires_at"`
+	FinalizingAt     *int64             `json:"finalizing_at"`
+	CompletedAt      *int64             `json:"completed_at"`
+	FailedAt         *int64             `json:"failed_at"`
+	ExpiredAt        *int64             `json:"expired_at"`
+	CancellingAt     *int64             `json:"cancelling_at"`
+	CancelledAt      *int64             `json:"cancelled_at"`
+	RequestCounts    BatchRequestCounts `json:"request_counts"`
+	Metadata         map[string]string  `json:"metadata"`
+}
+
+type BatchErrors struct {
+	Object string              `json:"object"`
+	Data   []models.BatchError `json:"data"`
+}
+
+type BatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+type ListObject[T any] struct {
+	Object  string  `json:"object"`
+	Data    []T     `json:"data"`
+	FirstID *string `json:"first_id,omitempty"`
+	LastID  *string `json:"last_id,omitempty"`
+	HasMore bool    `json:"has_more"`
+}
+
+type CreateBatchRequest struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+func toFileObject(file models.File) FileObject {
+	return FileObject{
+		ID:        file.FileID,
+		Object:    "file",
+		Bytes:     file.Bytes,
+		CreatedAt: file.CreatedAt.Unix(),
+		Filename:  file.Filename,
+		Purpose:   file.Purpose,
+	}
+}
+
+func toBatchObject(batch models.Batch) BatchObject {
+	res := BatchObject{
+		ID:               batch.BatchID,
+		Object:           "batch",
+		Endpoint:         batch.Endpoint,
+		InputFileID:      batch.InputFileID,
+		CompletionWindow: batch.CompletionWindow,
+		Status:           batch.Status,
+		OutputFileID:     lo.EmptyableToPtr(batch.OutputFileID),
+		ErrorFileID:      lo.EmptyableToPtr(batch.ErrorFileID),
+		CreatedAt:        batch.CreatedAt.Unix(),
+		InProgressAt:     unixPtr(batch.InProgressAt),
+		ExpiresAt:        batch.ExpiresAt.Unix(),
+		FinalizingAt:     unixPtr(batch.FinalizingAt),
+		CompletedAt:      unixPtr(batch.CompletedAt),
+		FailedAt:         unixPtr(batch.FailedAt),
+		ExpiredAt:        unixPtr(batch.ExpiredAt),
+		CancellingAt:     unixPtr(batch.CancellingAt),
+		CancelledAt:      unixPtr(batch.CancelledAt),
+		RequestCounts: BatchRequestCounts{
+			Total:     batch.Total,
+			Completed: batch.Completed,
+			Failed:    batch.Failed,
+		},
+		Metadata: batch.Metadata,
+	}
+	if len(batch.Errors) > 0 {
+		res.Errors = &BatchErrors{Object: "list", Data: batch.Errors}
+	}
+	return res
+}
+
+func unixPtr(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	return lo.ToPtr(t.Unix())
+}
+
+func authKeyID(c *gin.Context) uint {
+	id, _ := c.Request.Context().Value(consts.ContextKeyAuthKeyID).(uint)
+	return id
+}
+
+// findFile 仅能访问当前 AuthKey 的文件
+func findFile(c *gin.Context) (*models.File, bool) {
+	file, err := gorm.G[models.File](models.DB).Where("file_id = ?", c.Param("id")).Where("auth_key_id = ?", authKeyID(c)).First(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.ErrorWithHttpStatus(c, http.StatusNotFound, http.StatusNotFound, "No such file: "+c.Param("id"))
+			return nil, false
+		}
+		common.InternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &file, true
+}
+
+func findBatch(c *gin.Context) (*models.Batch, bool) {
+	batch, err := gorm.G[models.Batch](models.DB).Where("batch_id = ?", c.Param("id")).Where("auth_key_id = ?", authKeyID(c)).First(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.ErrorWithHttpStatus(c, http.StatusNotFound, http.StatusNotFound, "No such batch: "+c.Param("id"))
+			return nil, false
+		}
+		common.InternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &batch, true
+}
+
+// listLimit 解析 limit 参数，默认 20，最大 100
+func listLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		return 20
+	}
+	return min(limit, 100)
+}
+
+func UploadFile(c *gin.Context) {
+	purpose := c.PostForm("purpose")
+	if purpose != "batch" {
+		common.ErrorWithHttpStatus(c, http.StatusBadRequest, http.StatusBadRequest, "Only purpose 'batch' is supported")
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		common.ErrorWithHttpStatus(c, http.StatusBadRequest, http.StatusBadRequest, "Missing file: "+err.Error())
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+
+	fileID, err := service.NewFileID()
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	file := models.File{
+		FileID:    fileID,
+		AuthKeyID: authKeyID(c),
+		Filename:  header.Filename,
+		Purpose:   purpose,
+		Bytes:     len(content),
+		Content:   content,
+	}
+	if err := gorm.G[models.File](models.DB).Create(c.Request.Context(), &file); err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	common.SuccessRaw(c, toFileObject(file))
+}
+
+func ListFiles(c *gin.Context) {
+	query := gorm.G[models.File](models.DB).Omit("content").Where("auth_key_id = ?", authKeyID(c))
+	if purpose := c.Query("purpose"); purpose != "" {
+		query = query.Where("purpose = ?", purpose)
+	}
+	files, err := query.Order("id DESC").Limit(listLimit(c)).Find(c.Request.Context())
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	common.SuccessRaw(c, ListObject[FileObject]{
+		Object: "list",
+		Data:   lo.Map(files, func(file models.File, _ int) FileObject { return toFileObject(file) }),
+	})
+}
+
+func GetFile(c *gin.Context) {
+	file, ok := findFile(c)
+	if !ok {
+		return
+	}
+	common.SuccessRaw(c, toFileObject(*file))
+}
+
+func GetFileContent(c *gin.Context) {
+	file, ok := findFile(c)
+	if !ok {
+		return
+	}
+	c.Data(http.StatusOK, "application/octet-stream", file.Content)
+}
+
+func DeleteFile(c *gin.Context) {
+	file, ok := findFile(c)
+	if !ok {
+		return
+	}
+	if _, err := gorm.G[models.File](models.DB).Where("id = ?", file.ID).Delete(c.Request.Context()); err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	common.SuccessRaw(c, gin.H{
+		"id":      file.FileID,
+		"object":  "file",
+		"deleted": true,
+	})
+}
+
+func CreateBatch(c *gin.Context) {
+	var req CreateBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ErrorWithHttpStatus(c, http.StatusBadRequest, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	batch, err := service.CreateBatch(c.Request.Context(), req.InputFileID, req.Endpoint, lo.CoalesceOrEmpty(req.CompletionWindow, "24h"), req.Metadata)
+	if err != nil {
+		common.ErrorWithHttpStatus(c, http.StatusBadRequest, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.SuccessRaw(c, toBatchObject(*batch))
+}
+
+func ListBatches(c *gin.Context) {
+	ctx := c.Request.Context()
+	query := gorm.G[models.Batch](models.DB).Where("auth_key_id = ?", authKeyID(c))
+	// 游标分页，after 为上一页最后一个 batch id
+	if after := c.Query("after"); after != "" {
+		cursor, err := gorm.G[models.Batch](models.DB).Where("batch_id = ?", after).First(ctx)
+		if err != nil {
+			common.ErrorWithHttpStatus(c, http.StatusBadRequest, http.StatusBadRequest, "Invalid after cursor: "+after)
+			return
+		}
+		query = query.Where("id < ?", cursor.ID)
+	}
+	limit := listLimit(c)
+	batches, err := query.Order("id DESC").Limit(limit + 1).Find(ctx)
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	hasMore := len(batches) > limit
+	if hasMore {
+		batches = batches[:limit]
+	}
+	res := ListObject[BatchObject]{
+		Object:  "list",
+		Data:    lo.Map(batches, func(batch models.Batch, _ int) BatchObject { return toBatchObject(batch) }),
+		HasMore: hasMore,
+	}
+	if len(batches) > 0 {
+		res.FirstID = &res.Data[0].ID
+		res.LastID = &res.Data[len(res.Data)-1].ID
+	}
+	common.SuccessRaw(c, res)
+}
+
+func GetBatch(c *gin.Context) {
+	batch, ok := findBatch(c)
+	if !ok {
+		return
+	}
+	common.SuccessRaw(c, toBatchObject(*batch))
+}
+
+func CancelBatch(c *gin.Context) {
+	batch, ok := findBatch(c)
+	if !ok {
+		return
+	}
+	batch, err := service.CancelBatch(c.Request.Context(), *batch)
+	if err != nil {
+		common.ErrorWithHttpStatus(c, http.StatusConflict, http.StatusConflict, err.Error())
+		return
+	}
+	common.SuccessRaw(c, toBatchObject(*batch))
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/atopos31/llmio/balancers"
+	"github.com/atopos31/llmio/common"
+	"github.com/atopos31/llmio/models"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// BreakerInfo 关联的熔断状态
+type BreakerInfo struct {
+	ID            uint       `json:"id"` // 关联 ID
+	ModelID       uint       `json:"model_id"`
+	ModelName     string     `json:"model_name"`
+	ProviderName  string     `json:"provider_name"`
+	ProviderModel string     `json:"provider_model"`
+	Breaker       bool       `json:"breaker"` // 模型是否开启熔断
+	State         string     `json:"state"`   // closed / open / half-open
+	FailCount     int        `json:"fail_count"`
+	Expiry        *time.Time `json:"expiry"` // 冷却结束时间，手动熔断时为空
+	Forced        bool       `json:"forced"` // 是否手动熔断
+}
+
+// GetBreakers 列出各关联的熔断状态，可按 model_id 筛选
+func GetBreakers(c *gin.Context) {
+	ctx := c.Request.Context()
+	query := gorm.G[models.ModelWithProvider](models.DB).Order("id ASC")
+	if modelID := c.Query("model_id"); modelID != "" {
+		query = query.Where("model_id = ?", modelID)
+	}
+	modelProviders, err := query.Find(ctx)
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+
+	modelList, err := gorm.G[models.Model](models.DB).Where("id IN ?", lo.Map(modelProviders, func(mp models.ModelWithProvider, _ int) uint { return mp.ModelID })).Find(ctx)
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	providerList, err := gorm.G[models.Provider](models.DB).Where("id IN ?", lo.Map(modelProviders, func(mp models.ModelWithProvider, _ int) uint { return mp.ProviderID })).Find(ctx)
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	modelMap := lo.KeyBy(modelList, func(m models.Model) uint { return m.ID })
+	providerMap := lo.KeyBy(providerList, func(p models.Provider) uint { return p.ID })
+
+	stats := balancers.NodeStats()
+	results := make([]BreakerInfo, 0, len(modelProviders))
+	for _, mp := range modelProviders {
+		stat := stats[mp.ID]
+		info := BreakerInfo{
+			ID:            mp.ID,
+			ModelID:       mp.ModelID,
+			ModelName:     modelMap[mp.ModelID].Name,
+			ProviderName:  providerMap[mp.ProviderID].Name,
+			ProviderModel: mp.ProviderModel,
+			Breaker:       lo.FromPtrOr(modelMap[mp.ModelID].Breaker, false),
+			State:         stat.State.String(),
+			FailCount:     stat.FailCount,
+			Forced:        stat.Forced,
+		}
+		if !stat.Expiry.IsZero() {
+			info.Expiry = &stat.Expiry
+		}
+		results = append(results, info)
+	}
+
+	common.Success(c, results)
+}
+
+// ForceOpenBreaker 手动熔断关联，使其不再被选择，直到手动恢复
+func ForceOpenBreaker(c *gin.Context) {
+	id, ok := breakerModelProviderID(c)
+	if !ok {
+		return
+	}
+	balancers.ForceOpen(id)
+	common.Success(c, nil)
+}
+
+// ForceCloseBreaker 手动恢复关联并清空失败次数
+func ForceCloseBreaker(c *gin.Context) {
+	id, ok := breakerModelProviderID(c)
+	if !ok {
+		return
+	}
+	balancers.ForceClose(id)
+	common.Success(c, nil)
+}
+
+func breakerModelProviderID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		common.BadRequest(c, "Invalid ID format")
+		return 0, false
+	}
+	if _, err := gorm.G[models.ModelWithProvider](models.DB).Where("id = ?", id).First(c.Request.Context()); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			common.NotFound(c, "Model-provider association not found")
+			return 0, false
+		}
+		common.InternalServerError(c, "Database error: "+err.Error())
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetBreakerEvents 分页查询熔断状态变更记录，可按 model_provider_id 筛选
+func GetBreakerEvents(c *gin.Context) {
+	params, err := common.ParsePagination(c)
+	if err != nil {
+		common.BadRequest(c, err.Error())
+		return
+	}
+
+	query := models.DB.Model(&models.BreakerEvent{})
+	if id := c.Query("model_provider_id"); id != "" {
+		query = query.Where("model_with_provider_id = ?", id)
+	}
+
+	events := make([]models.BreakerEvent, 0)
+	total, err := common.PaginateQuery(query.Order("id DESC"), params, &events)
+	if err != nil {
+		common.InternalServerError(c, "Failed to query breaker events: "+err.Error())
+		return
+	}
+
+	common.Success(c, common.NewPaginationResponse(events, total, params))
+}
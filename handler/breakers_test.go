@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/atopos31/llmio/balancers"
+	"github.com/atopos31/llmio/common"
+	"github.com/atopos31/llmio/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBreakerHandlers(t *testing.T) {
+	db := setupTestDB(t, &models.Model{}, &models.Provider{}, &models.ModelWithProvider{}, &models.BreakerEvent{})
+
+	breaker := true
+	db.Create(&models.Model{Name: "claude", Breaker: &breaker})
+	db.Create(&models.Provider{Name: "primary"})
+	mp := models.ModelWithProvider{ModelID: 1, ProviderID: 1, ProviderModel: "claude-sonnet-4-5"}
+	db.Create(&mp)
+	t.Cleanup(func() { balancers.ForceClose(mp.ID) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/breakers", GetBreakers)
+	router.GET("/api/breakers/events", GetBreakerEvents)
+	router.POST("/api/breakers/:id/open", ForceOpenBreaker)
+	router.POST("/api/breakers/:id/close", ForceCloseBreaker)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/breakers/99/open", nil))
+	var notFound common.Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &notFound); err != nil || notFound.Code != 404 {
+		t.Fatalf("open unknown association: %s", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/breakers/1/open", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("open: status %d, body %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/breakers", nil))
+	var response struct {
+		Data []BreakerInfo `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Data) != 1 {
+		t.Fatalf("breakers = %+v", response.Data)
+	}
+	info := response.Data[0]
+	if info.State != "open" || !info.Forced || info.ModelName != "claude" || info.ProviderName != "primary" || !info.Breaker {
+		t.Fatalf("unexpected breaker info: %+v", info)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/breakers/1/close", nil))
+	if state := balancers.NodeStats()[mp.ID].State; recorder.Code != http.StatusOK || state != balancers.StateClosed {
+		t.Fatalf("close: status %d, state %v", recorder.Code, state)
+	}
+}
@@ -45,8 +45,52 @@ func Messages(c *gin.Context) {
 	chatHandler(c, service.BeforerAnthropic, service.ProcesserAnthropic, consts.StyleAnthropic)
 }
 
+// CompletionsHandler 旧版文本补全及 FIM，响应与 chat 一致使用 usage 字段
+func CompletionsHandler(c *gin.Context) {
+	chatHandler(c, service.BeforerOpenAICompletions, service.ProcesserOpenAI, consts.StyleOpenAI)
+}
+
+func EmbeddingsHandler(c *gin.Context) {
+	chatHandler(c, service.BeforerOpenAIEmbeddings, service.ProcesserOpenAIEmbeddings, consts.StyleOpenAI)
+}
+
+// RerankHandler Cohere / Jina 风格重排序，转发至提供商的 /rerank
+func RerankHandler(c *gin.Context) {
+	chatHandler(c, service.BeforerRerank, service.ProcesserRerank, consts.StyleOpenAI)
+}
+
+func ImageGenerationsHandler(c *gin.Context) {
+	chatHandler(c, service.NewBeforerImages(consts.EndpointImageGenerations), service.ProcesserOpenAIImages, consts.StyleOpenAI)
+}
+
+// ImageEditsHandler 图片编辑请求为 multipart/form-data
+func ImageEditsHandler(c *gin.Context) {
+	beforer := service.NewBeforerImages(consts.EndpointImageEdits)
+	if contentType := c.GetHeader("Content-Type"); strings.HasPrefix(contentType, "multipart/form-data") {
+		beforer = service.NewBeforerMultipart(contentType, consts.EndpointImageEdits)
+	}
+	chatHandler(c, beforer, service.ProcesserOpenAIImages, consts.StyleOpenAI)
+}
+
+// AudioTranscriptionsHandler 语音转文字请求为 multipart/form-data
+func AudioTranscriptionsHandler(c *gin.Context) {
+	beforer := service.NewBeforerMultipart(c.GetHeader("Content-Type"), consts.EndpointAudioTranscriptions)
+	chatHandler(c, beforer, service.ProcesserOpenAITranscriptions, consts.StyleOpenAI)
+}
+
+// OllamaChatHandler Ollama /api/chat，流式响应为 NDJSON
+func OllamaChatHandler(c *gin.Context) {
+	chatHandler(c, service.BeforerOllama, service.ProcesserOllama, consts.StyleOllama)
+}
+
+// AudioSpeechHandler 文字转语音，音频二进制原样流式转发
+func AudioSpeechHandler(c *gin.Context) {
+	chatHandler(c, service.BeforerOpenAISpeech, service.ProcesserOpenAISpeech, consts.StyleOpenAI)
+}
+
 // GeminiGenerateContentHandler 转发 Gemini 原生接口:
 // POST /v1beta/models/{model}:generateContent
+// POST /v1beta/models/{model}:embedContent
 func GeminiGenerateContentHandler(c *gin.Context) {
 	modelAction := strings.TrimPrefix(c.Param("modelAction"), "/")
 	model, method, ok := strings.Cut(modelAction, ":")
@@ -60,6 +104,12 @@ func GeminiGenerateContentHandler(c *gin.Context) {
 		stream = false
 	case "streamGenerateContent":
 		stream = true
+	case "embedContent":
+		chatHandler(c, service.NewBeforerGeminiEmbeddings(model, consts.EndpointEmbeddings), service.ProcesserGeminiEmbeddings, consts.StyleGemini)
+		return
+	case "batchEmbedContents":
+		chatHandler(c, service.NewBeforerGeminiEmbeddings(model, consts.EndpointBatchEmbeddings), service.ProcesserGeminiEmbeddings, consts.StyleGemini)
+		return
 	default:
 		common.BadRequest(c, "Unsupported Gemini method: "+method)
 		return
@@ -97,6 +147,11 @@ func chatHandler(c *gin.Context, preProcessor service.Beforer, postProcessor ser
 		common.ErrorWithHttpStatus(c, http.StatusForbidden, http.StatusForbidden, "auth key has no permission to use this model")
 		return
 	}
+	// 解析 Responses 接口的 previous_response_id
+	if err := service.ResolveConversation(ctx, style, before); err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
 	// 按模型获取可用 provider
 	providersWithMeta, err := service.ProvidersWithMetaBymodelsName(ctx, style, *before)
 	if err != nil {
@@ -128,11 +183,11 @@ func chatHandler(c *gin.Context, preProcessor service.Beforer, postProcessor ser
 	// 异步处理输出并记录 tokens
 	go service.RecordLog(context.Background(), startReq, pr, postProcessor, logId, *before, providersWithMeta.IOLog)
 
-	writeHeader(c, before.Stream, res.Header)
+	writeHeader(c, style, before.Stream, res.Header)
 
 	// 流式响应使用 flushWriter 确保数据实时发送
 	var writer io.Writer = c.Writer
-	if before.Stream {
+	if before.Streaming() {
 		writer = &flushWriter{w: c.Writer}
 	}
 
@@ -145,7 +200,7 @@ func chatHandler(c *gin.Context, preProcessor service.Beforer, postProcessor ser
 	pw.Close()
 }
 
-func writeHeader(c *gin.Context, stream bool, header http.Header) {
+func writeHeader(c *gin.Context, style string, stream bool, header http.Header) {
 	for k, values := range header {
 		for _, value := range values {
 			c.Writer.Header().Add(k, value)
@@ -153,7 +208,12 @@ func writeHeader(c *gin.Context, stream bool, header http.Header) {
 	}
 
 	if stream {
-		c.Header("Content-Type", "text/event-stream")
+		contentType := "text/event-stream"
+		// Ollama 流式响应为 NDJSON
+		if style == consts.StyleOllama {
+			contentType = "application/x-ndjson"
+		}
+		c.Header("Content-Type", contentType)
 		c.Header("Cache-Control", "no-cache")
 		c.Header("Connection", "keep-alive")
 		c.Header("X-Accel-Buffering", "no")
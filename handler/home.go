@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/atopos31/llmio/common"
+	"github.com/atopos31/llmio/consts"
 	"github.com/atopos31/llmio/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -162,12 +163,29 @@ type ModelTokenUsage struct {
 	Tokens int64  `json:"tokens"`
 }
 
+type ModelUnitUsage struct {
+	Model    string  `json:"model"`
+	Endpoint string  `json:"endpoint"`
+	Unit     string  `json:"unit"`
+	Units    float64 `json:"units"`
+	Calls    int64   `json:"calls"`
+}
+
 type ProviderModelCall struct {
 	Provider string `json:"provider"`
 	Model    string `json:"model"`
 	Calls    int64  `json:"calls"`
 }
 
+// StrategyCacheUsage 各负载均衡策略的提示词缓存命中情况
+type StrategyCacheUsage struct {
+	Strategy     string  `json:"strategy"`
+	Calls        int64   `json:"calls"`
+	PromptTokens int64   `json:"prompt_tokens"` // 包含缓存读取的输入 token
+	CachedTokens int64   `json:"cached_tokens"`
+	HitRate      float64 `json:"hit_rate"` // cached_tokens / prompt_tokens
+}
+
 func Counts(c *gin.Context) {
 	results := make([]Count, 0)
 	if err := models.DB.
@@ -219,6 +237,66 @@ func ModelTokenUsages(c *gin.Context) {
 	common.Success(c, results)
 }
 
+// ModelUnitUsages 统计图片、音频等非 token 计量接口的用量
+func ModelUnitUsages(c *gin.Context) {
+	hours, err := strconv.Atoi(c.Param("hours"))
+	if err != nil || hours <= 0 {
+		common.BadRequest(c, "Invalid hours parameter")
+		return
+	}
+
+	results := make([]ModelUnitUsage, 0)
+	startTime := time.Now().Add(-time.Duration(hours) * time.Hour)
+	if err := models.DB.
+		Model(&models.ChatLog{}).
+		Select("name as model, endpoint, unit, COALESCE(SUM(units), 0) as units, COUNT(*) as calls").
+		Where("created_at >= ?", startTime).
+		Where("unit <> ''").
+		Group("name, endpoint, unit").
+		Order("units DESC, name ASC").
+		Scan(&results).Error; err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+
+	common.Success(c, results)
+}
+
+// StrategyCacheUsages 按负载均衡策略统计成功请求的缓存 token，用于比较 sticky 等策略的缓存命中率
+func StrategyCacheUsages(c *gin.Context) {
+	hours, err := strconv.Atoi(c.Param("hours"))
+	if err != nil || hours <= 0 {
+		common.BadRequest(c, "Invalid hours parameter")
+		return
+	}
+
+	results := make([]StrategyCacheUsage, 0)
+	startTime := time.Now().Add(-time.Duration(hours) * time.Hour)
+	strategyExpr := "COALESCE(NULLIF(strategy, ''), '-')"
+	cachedExpr := "COALESCE(json_extract(prompt_tokens_details, '$.cached_tokens'), 0)"
+	// Anthropic 的 input_tokens 不包含缓存读取，需加回缓存 token 才与其他协议口径一致
+	promptExpr := "CASE WHEN style = ? THEN prompt_tokens + " + cachedExpr + " ELSE prompt_tokens END"
+	if err := models.DB.
+		Model(&models.ChatLog{}).
+		Select(strategyExpr+" as strategy, COUNT(*) as calls, COALESCE(SUM("+promptExpr+"), 0) as prompt_tokens, "+
+			"COALESCE(SUM("+cachedExpr+"), 0) as cached_tokens", consts.StyleAnthropic).
+		Where("created_at >= ?", startTime).
+		Where("status = ?", consts.StatusSuccess).
+		Group(strategyExpr).
+		Order("calls DESC, strategy ASC").
+		Scan(&results).Error; err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	for i := range results {
+		if results[i].PromptTokens > 0 {
+			results[i].HitRate = min(float64(results[i].CachedTokens)/float64(results[i].PromptTokens), 1)
+		}
+	}
+
+	common.Success(c, results)
+}
+
 func ProviderModelCalls(c *gin.Context) {
 	hours, err := strconv.Atoi(c.Param("hours"))
 	if err != nil || hours <= 0 {
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/atopos31/llmio/common"
+	"github.com/atopos31/llmio/consts"
 	"github.com/atopos31/llmio/models"
 	"github.com/gin-gonic/gin"
 	"github.com/glebarez/sqlite"
@@ -215,3 +216,97 @@ func TestProviderModelCalls_RejectsInvalidHours(t *testing.T) {
 		t.Fatalf("expected bad request code, got %d", response.Code)
 	}
 }
+
+func TestModelUnitUsages_GroupsByModelAndUnit(t *testing.T) {
+	cleanup := setupHomeTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	logs := []models.ChatLog{
+		{Name: "gpt-image-1", Endpoint: "image_generations", Unit: "images", Units: 2, Model: gorm.Model{CreatedAt: now.Add(-1 * time.Hour)}},
+		{Name: "gpt-image-1", Endpoint: "image_generations", Unit: "images", Units: 1, Model: gorm.Model{CreatedAt: now.Add(-2 * time.Hour)}},
+		{Name: "gpt-image-1", Endpoint: "image_edits", Unit: "images", Units: 4, Model: gorm.Model{CreatedAt: now.Add(-3 * time.Hour)}},
+		{Name: "gpt-4.1", Endpoint: "chat", Usage: models.Usage{TotalTokens: 100}, Model: gorm.Model{CreatedAt: now.Add(-1 * time.Hour)}},
+		{Name: "gpt-image-1", Endpoint: "image_generations", Unit: "images", Units: 10, Model: gorm.Model{CreatedAt: now.Add(-48 * time.Hour)}},
+	}
+	if err := models.DB.Create(&logs).Error; err != nil {
+		t.Fatalf("failed to seed chat logs: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Params = gin.Params{{Key: "hours", Value: "24"}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/api/metrics/model-units/24", nil)
+
+	ModelUnitUsages(ctx)
+
+	var response common.Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	payload, err := json.Marshal(response.Data)
+	if err != nil {
+		t.Fatalf("failed to re-encode data: %v", err)
+	}
+	var usages []ModelUnitUsage
+	if err := json.Unmarshal(payload, &usages); err != nil {
+		t.Fatalf("failed to decode usages: %v", err)
+	}
+
+	if len(usages) != 2 {
+		t.Fatalf("expected 2 unit usage rows, got %#v", usages)
+	}
+	if usages[0].Endpoint != "image_edits" || usages[0].Units != 4 {
+		t.Fatalf("unexpected top unit usage: %#v", usages[0])
+	}
+	if usages[1].Endpoint != "image_generations" || usages[1].Units != 3 || usages[1].Calls != 2 {
+		t.Fatalf("unexpected generation unit usage: %#v", usages[1])
+	}
+}
+
+func TestStrategyCacheUsages(t *testing.T) {
+	cleanup := setupHomeTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	logs := []models.ChatLog{
+		{Strategy: "sticky", Status: "success", Usage: models.Usage{PromptTokens: 1000, PromptTokensDetails: models.PromptTokensDetails{CachedTokens: 800}}, Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}},
+		{Strategy: "sticky", Status: "success", Usage: models.Usage{PromptTokens: 1000, PromptTokensDetails: models.PromptTokensDetails{CachedTokens: 600}}, Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}},
+		{Strategy: "lottery", Status: "success", Usage: models.Usage{PromptTokens: 1000, PromptTokensDetails: models.PromptTokensDetails{CachedTokens: 100}}, Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}},
+		{Strategy: "lottery", Status: "error", Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}},
+		// Anthropic 的 input_tokens 不包含缓存读取
+		{Strategy: "priority", Style: consts.StyleAnthropic, Status: "success", Usage: models.Usage{PromptTokens: 100, PromptTokensDetails: models.PromptTokensDetails{CachedTokens: 900}}, Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}},
+	}
+	if err := models.DB.Create(&logs).Error; err != nil {
+		t.Fatalf("failed to seed chat logs: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Params = gin.Params{{Key: "hours", Value: "24"}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/api/metrics/strategy-cache/24", nil)
+
+	StrategyCacheUsages(ctx)
+
+	var response struct {
+		Data []StrategyCacheUsage `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Data) != 3 {
+		t.Fatalf("expected 3 strategies, got %+v", response.Data)
+	}
+	sticky := response.Data[0]
+	if sticky.Strategy != "sticky" || sticky.Calls != 2 || sticky.CachedTokens != 1400 || sticky.HitRate != 0.7 {
+		t.Fatalf("unexpected sticky usage: %+v", sticky)
+	}
+	if lottery := response.Data[1]; lottery.Strategy != "lottery" || lottery.Calls != 1 || lottery.CachedTokens != 100 {
+		t.Fatalf("unexpected lottery usage: %+v", lottery)
+	}
+	if priority := response.Data[2]; priority.PromptTokens != 1000 || priority.HitRate != 0.9 {
+		t.Fatalf("unexpected anthropic-style usage: %+v", priority)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"slices"
+	"time"
 
 	"github.com/atopos31/llmio/common"
 	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/converters"
 	"github.com/atopos31/llmio/models"
 	"github.com/atopos31/llmio/providers"
 	"github.com/atopos31/llmio/service"
@@ -15,7 +17,7 @@ import (
 
 func OpenAIModelsHandler(c *gin.Context) {
 	ctx := c.Request.Context()
-	models, err := service.ModelsByTypes(ctx, consts.StyleOpenAI, consts.StyleOpenAIRes)
+	models, err := service.ModelsByTypes(ctx, providers.TypesOf(slices.Concat(converters.Targets(consts.StyleOpenAI), converters.Targets(consts.StyleOpenAIRes))...)...)
 	if err != nil {
 		common.InternalServerError(c, err.Error())
 		return
@@ -42,7 +44,7 @@ func OpenAIModelsHandler(c *gin.Context) {
 
 func AnthropicModelsHandler(c *gin.Context) {
 	ctx := c.Request.Context()
-	models, err := service.ModelsByTypes(ctx, consts.StyleAnthropic)
+	models, err := service.ModelsByTypes(ctx, providers.TypesOf(converters.Targets(consts.StyleAnthropic)...)...)
 	if err != nil {
 		common.InternalServerError(c, err.Error())
 		return
@@ -79,7 +81,7 @@ type GeminiModel struct {
 
 func GeminiModelsHandler(c *gin.Context) {
 	ctx := c.Request.Context()
-	models, err := service.ModelsByTypes(ctx, consts.StyleGemini)
+	models, err := service.ModelsByTypes(ctx, providers.TypesOf(converters.Targets(consts.StyleGemini)...)...)
 	if err != nil {
 		common.InternalServerError(c, err.Error())
 		return
@@ -105,6 +107,44 @@ func GeminiModelsHandler(c *gin.Context) {
 	})
 }
 
+type OllamaTagsResponse struct {
+	Models []OllamaModel `json:"models"`
+}
+
+type OllamaModel struct {
+	Name       string    `json:"name"`
+	Model      string    `json:"model"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
+}
+
+// OllamaTagsHandler 模拟 Ollama /api/tags，供只支持 Ollama 接口的工具列出模型
+func OllamaTagsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	models, err := service.ModelsByTypes(ctx, providers.TypesOf(converters.Targets(consts.StyleOllama)...)...)
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	models, err = filterByAuthKey(ctx, models)
+	if err != nil {
+		common.InternalServerError(c, err.Error())
+		return
+	}
+	resModels := make([]OllamaModel, 0, len(models))
+	for _, model := range models {
+		resModels = append(resModels, OllamaModel{
+			Name:       model.Name,
+			Model:      model.Name,
+			ModifiedAt: model.UpdatedAt,
+		})
+	}
+	common.SuccessRaw(c, OllamaTagsResponse{
+		Models: resModels,
+	})
+}
+
 func filterByAuthKey(ctx context.Context, inModels []models.Model) ([]models.Model, error) {
 	// 验证是否为允许全部模型
 	allowAll, ok := ctx.Value(consts.ContextKeyAllowAllModel).(bool)
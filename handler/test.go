@@ -77,6 +77,17 @@ const (
 			}
 		]
 	}`
+
+	testOllama = `{
+		"model": "llama3.2",
+		"stream": false,
+		"messages": [
+			{
+				"role": "user",
+				"content": "Please reply me yes or no"
+			}
+		]
+	}`
 )
 
 func ProviderTestHandler(c *gin.Context) {
@@ -105,7 +116,7 @@ func ProviderTestHandler(c *gin.Context) {
 
 	client := providers.GetClientWithProxy(time.Second*time.Duration(30), providerInstance.GetProxy())
 	var testBody []byte
-	switch chatModel.Type {
+	switch providers.StyleOf(chatModel.Type) {
 	case consts.StyleOpenAI:
 		testBody = []byte(testOpenAI)
 	case consts.StyleAnthropic:
@@ -114,6 +125,8 @@ func ProviderTestHandler(c *gin.Context) {
 		testBody = []byte(testOpenAIRes)
 	case consts.StyleGemini:
 		testBody = []byte(testGemini)
+	case consts.StyleOllama:
+		testBody = []byte(testOllama)
 	default:
 		common.BadRequest(c, "Invalid provider type")
 		return
@@ -310,12 +323,16 @@ func FindChatModel(ctx context.Context, id string) (*ChatModel, error) {
 	if err != nil {
 		return nil, err
 	}
+	config, err := service.AdminProviderConfig(provider)
+	if err != nil {
+		return nil, err
+	}
 
 	return &ChatModel{
 		Name:            provider.Name,
 		Type:            provider.Type,
 		Model:           modelWithProvider.ProviderModel,
-		Config:          provider.Config,
+		Config:          config,
 		Proxy:           provider.Proxy,
 		WithHeader:      modelWithProvider.WithHeader,
 		CustomerHeaders: modelWithProvider.CustomerHeaders,
@@ -9,3 +9,8 @@ import (
 func GetVersion(c *gin.Context) {
 	common.Success(c, consts.Version)
 }
+
+// OllamaVersionHandler 部分 Ollama 客户端连接前会探测版本
+func OllamaVersionHandler(c *gin.Context) {
+	common.SuccessRaw(c, gin.H{"version": consts.OllamaVersion})
+}
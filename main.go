@@ -15,6 +15,7 @@ import (
 	"github.com/atopos31/llmio/middleware"
 	"github.com/atopos31/llmio/models"
 	"github.com/atopos31/llmio/pkg/env"
+	"github.com/atopos31/llmio/service"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	_ "golang.org/x/crypto/x509roots/fallback"
@@ -27,9 +28,18 @@ func init() {
 }
 
 func main() {
+	// 恢复重启前的熔断状态
+	if err := service.InitBreakers(context.Background()); err != nil {
+		slog.Error("init breakers error", "error", err)
+	}
+	// 恢复重启前未完成的批处理任务
+	if err := service.ResumeBatches(context.Background()); err != nil {
+		slog.Error("resume batches error", "error", err)
+	}
+
 	router := gin.Default()
 	// gzip压缩
-	router.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/openai", "/anthropic", "/gemini", "/v1"})))
+	router.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/openai", "/anthropic", "/gemini", "/ollama", "/v1"})))
 	// 跨域
 	router.Use(middleware.Cors())
 	// webui
@@ -49,6 +59,23 @@ func main() {
 			v1.GET("/models", handler.OpenAIModelsHandler)
 			v1.POST("/chat/completions", handler.ChatCompletionsHandler)
 			v1.POST("/responses", handler.ResponsesHandler)
+			v1.POST("/completions", handler.CompletionsHandler)
+			v1.POST("/embeddings", handler.EmbeddingsHandler)
+			v1.POST("/rerank", handler.RerankHandler)
+			v1.POST("/images/generations", handler.ImageGenerationsHandler)
+			v1.POST("/images/edits", handler.ImageEditsHandler)
+			v1.POST("/audio/transcriptions", handler.AudioTranscriptionsHandler)
+			v1.POST("/audio/speech", handler.AudioSpeechHandler)
+
+			v1.POST("/files", handler.UploadFile)
+			v1.GET("/files", handler.ListFiles)
+			v1.GET("/files/:id", handler.GetFile)
+			v1.GET("/files/:id/content", handler.GetFileContent)
+			v1.DELETE("/files/:id", handler.DeleteFile)
+			v1.POST("/batches", handler.CreateBatch)
+			v1.GET("/batches", handler.ListBatches)
+			v1.GET("/batches/:id", handler.GetBatch)
+			v1.POST("/batches/:id/cancel", handler.CancelBatch)
 		}
 	}
 
@@ -76,12 +103,40 @@ func main() {
 		}
 	}
 
+	// ollama
+	ollama := router.Group("/ollama")
+	{
+		ollama.GET("/api/version", handler.OllamaVersionHandler)
+
+		api := ollama.Group("/api", authOpenAI)
+		{
+			api.GET("/tags", handler.OllamaTagsHandler)
+			api.POST("/chat", handler.OllamaChatHandler)
+		}
+	}
+
 	// 兼容性保留
 	v1 := router.Group("/v1")
 	{
 		v1.GET("/models", authOpenAI, handler.OpenAIModelsHandler)
 		v1.POST("/chat/completions", authOpenAI, handler.ChatCompletionsHandler)
 		v1.POST("/responses", authOpenAI, handler.ResponsesHandler)
+		v1.POST("/completions", authOpenAI, handler.CompletionsHandler)
+		v1.POST("/embeddings", authOpenAI, handler.EmbeddingsHandler)
+		v1.POST("/rerank", authOpenAI, handler.RerankHandler)
+		v1.POST("/images/generations", authOpenAI, handler.ImageGenerationsHandler)
+		v1.POST("/images/edits", authOpenAI, handler.ImageEditsHandler)
+		v1.POST("/audio/transcriptions", authOpenAI, handler.AudioTranscriptionsHandler)
+		v1.POST("/audio/speech", authOpenAI, handler.AudioSpeechHandler)
+		v1.POST("/files", authOpenAI, handler.UploadFile)
+		v1.GET("/files", authOpenAI, handler.ListFiles)
+		v1.GET("/files/:id", authOpenAI, handler.GetFile)
+		v1.GET("/files/:id/content", authOpenAI, handler.GetFileContent)
+		v1.DELETE("/files/:id", authOpenAI, handler.DeleteFile)
+		v1.POST("/batches", authOpenAI, handler.CreateBatch)
+		v1.GET("/batches", authOpenAI, handler.ListBatches)
+		v1.GET("/batches/:id", authOpenAI, handler.GetBatch)
+		v1.POST("/batches/:id/cancel", authOpenAI, handler.CancelBatch)
 		v1.POST("/messages", authAnthropic, handler.Messages)
 		v1.POST("/messages/count_tokens", authAnthropic, handler.CountTokens)
 	}
@@ -93,12 +148,15 @@ func main() {
 		api.GET("/metrics/hourly/:hours", handler.HourlyMetrics)
 		api.GET("/metrics/counts", handler.Counts)
 		api.GET("/metrics/model-tokens/:hours", handler.ModelTokenUsages)
+		api.GET("/metrics/model-units/:hours", handler.ModelUnitUsages)
 		api.GET("/metrics/provider-model-calls/:hours", handler.ProviderModelCalls)
+		api.GET("/metrics/strategy-cache/:hours", handler.StrategyCacheUsages)
 		api.GET("/metrics/projects", handler.ProjectCounts)
 		// Provider management
 		api.GET("/providers/template", handler.GetProviderTemplates)
 		api.GET("/providers", handler.GetProviders)
 		api.GET("/providers/models/:id", handler.GetProviderModels)
+		api.GET("/providers/:id/keys", handler.GetProviderKeys)
 		api.POST("/providers", handler.CreateProvider)
 		api.PUT("/providers/:id", handler.UpdateProvider)
 		api.DELETE("/providers/:id", handler.DeleteProvider)
@@ -119,6 +177,12 @@ func main() {
 		api.PATCH("/model-providers/:id/status", handler.UpdateModelProviderStatus)
 		api.DELETE("/model-providers/:id", handler.DeleteModelProvider)
 
+		// Circuit breakers
+		api.GET("/breakers", handler.GetBreakers)
+		api.GET("/breakers/events", handler.GetBreakerEvents)
+		api.POST("/breakers/:id/open", handler.ForceOpenBreaker)
+		api.POST("/breakers/:id/close", handler.ForceCloseBreaker)
+
 		// System status and monitoring
 		api.GET("/version", handler.GetVersion)
 		api.GET("/logs", handler.GetRequestLogs)
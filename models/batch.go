@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// File Files 接口上传的文件以及 Batch 生成的结果文件
+type File struct {
+	gorm.Model
+	FileID    string `gorm:"uniqueIndex"`
+	AuthKeyID uint   `gorm:"index"` // 上传文件的 AuthKey，仅其本身可访问
+	Filename  string
+	Purpose   string // batch / batch_output
+	Bytes     int
+	Content   []byte
+}
+
+// Batch 模拟 OpenAI Batch 接口的批处理任务
+type Batch struct {
+	gorm.Model
+	BatchID          string   `gorm:"uniqueIndex"`
+	AuthKeyID        uint     `gorm:"index"`
+	AllowAll         bool     // 创建时 AuthKey 是否允许所有模型
+	AllowModels      []string `gorm:"serializer:json"` // 创建时 AuthKey 允许的模型
+	Endpoint         string   // 如 /v1/chat/completions
+	InputFileID      string
+	OutputFileID     string
+	ErrorFileID      string
+	CompletionWindow string
+	Status           string            `gorm:"index"`
+	Errors           []BatchError      `gorm:"serializer:json"` // 输入文件校验错误
+	Metadata         map[string]string `gorm:"serializer:json"`
+	Total            int
+	Completed        int
+	Failed           int
+	ExpiresAt        time.Time
+	InProgressAt     *time.Time
+	FinalizingAt     *time.Time
+	CompletedAt      *time.Time
+	FailedAt         *time.Time
+	ExpiredAt        *time.Time
+	CancellingAt     *time.Time
+	CancelledAt      *time.Time
+}
+
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    *int   `json:"line,omitempty"`
+}
+
+// BatchResult 批处理中单个请求的结果，重启后据此跳过已完成的请求
+type BatchResult struct {
+	gorm.Model
+	BatchID string `gorm:"index"`
+	Line    int
+	Failed  bool
+	Output  string // 结果文件中的一行 JSON
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BreakerConfig 模型级熔断参数，零值项使用默认值
+type BreakerConfig struct {
+	MaxFailures int     `json:"max_failures"` // 失败次数阈值
+	SleepWindow int     `json:"sleep_window"` // 熔断冷却时间 单位秒
+	MaxRequests int     `json:"max_requests"` // 半开状态下恢复所需的成功次数
+	FailureRate float64 `json:"failure_rate"` // 窗口内失败率阈值 0-1，0 表示不启用
+	MinRequests int     `json:"min_requests"` // 失败率生效所需的窗口内最少请求数
+	Window      int     `json:"window"`       // 失败率统计窗口 单位秒
+}
+
+// BreakerState 关联的熔断状态，状态变更时写入，重启后恢复
+type BreakerState struct {
+	gorm.Model
+	ModelWithProviderID uint      `gorm:"uniqueIndex"`
+	State               int       // 0 closed / 1 open / 2 half-open
+	Expiry              time.Time // 熔断冷却结束时间
+	Forced              bool      // 手动熔断
+}
+
+// BreakerEvent 熔断状态变更记录
+type BreakerEvent struct {
+	gorm.Model
+	ModelWithProviderID uint   `gorm:"index"`
+	From                string // closed / open / half-open
+	To                  string
+	FailCount           int    // 变更前的失败次数
+	Reason              string // 触发原因
+	Expiry              time.Time
+}
@@ -10,6 +10,7 @@ type Config struct {
 
 const (
 	KeyAnthropicCountTokens = "anthropic_count_tokens"
+	KeySmartRouting         = "smart_routing"
 )
 
 type AnthropicCountTokens struct {
@@ -18,3 +19,19 @@ type AnthropicCountTokens struct {
 	Version string `json:"version"`
 	Proxy   string `json:"proxy,omitempty"`
 }
+
+// SmartRouting smart 策略的评分参数，关闭时 smart 策略按静态权重抽取
+type SmartRouting struct {
+	EnableSmartRouting  bool    `json:"enable_smart_routing"`
+	SuccessRateWeight   float64 `json:"success_rate_weight"`   // 成功率在评分中的占比
+	ResponseTimeWeight  float64 `json:"response_time_weight"`  // 首字延迟在评分中的占比
+	DecayThresholdHours int     `json:"decay_threshold_hours"` // 统计窗口，日志权重随时间线性衰减至 0
+	MinWeight           int     `json:"min_weight"`            // 动态权重下限
+}
+
+var DefaultSmartRouting = SmartRouting{
+	SuccessRateWeight:   0.7,
+	ResponseTimeWeight:  0.3,
+	DecayThresholdHours: 24,
+	MinWeight:           1,
+}
@@ -30,6 +30,12 @@ func Init(ctx context.Context, path string) {
 		&ChatIO{},
 		&Config{},
 		&AuthKey{},
+		&ResponseConversation{},
+		&File{},
+		&Batch{},
+		&BatchResult{},
+		&BreakerState{},
+		&BreakerEvent{},
 	); err != nil {
 		panic(err)
 	}
@@ -26,14 +26,16 @@ type AnthropicConfig struct {
 
 type Model struct {
 	gorm.Model
-	Name         string
-	Remark       string
-	MaxRetry     int    // 重试次数限制
-	TimeOut      int    // 超时时间 单位秒
-	IOLog        *bool  // 是否记录IO
-	Strategy     string // 负载均衡策略 默认 lottery
-	Breaker      *bool  // 是否开启熔断
-	DisplayOrder int    // 模型展示顺序，值越大越靠前
+	Name          string
+	Remark        string
+	MaxRetry      int           // 重试次数限制
+	TimeOut       int           // 超时时间 单位秒
+	IOLog         *bool         // 是否记录IO
+	Strategy      string        // 负载均衡策略 默认 lottery
+	Breaker       *bool         // 是否开启熔断
+	BreakerConfig BreakerConfig `gorm:"serializer:json"` // 熔断参数
+	DisplayOrder  int           // 模型展示顺序，值越大越靠前
+	SessionKey    string        // sticky 策略的会话标识来源 header:<名称> / body:<路径> / prompt，空为自动
 }
 
 type ModelWithProvider struct {
@@ -48,6 +50,10 @@ type ModelWithProvider struct {
 	Status           *bool             // 是否启用
 	CustomerHeaders  map[string]string `gorm:"serializer:json"` // 自定义headers
 	Weight           int
+	Tier             int     // 优先级分组，数值小的组全部不可用后才使用下一组
+	InputPrice       float64 // 输入单价 每百万 token
+	OutputPrice      float64 // 输出单价 每百万 token
+	CachedPrice      float64 // 缓存命中输入单价 每百万 token
 }
 
 type ChatLog struct {
@@ -58,10 +64,12 @@ type ChatLog struct {
 	ProviderName  string `gorm:"index"`
 	Status        string `gorm:"index"` // error or success
 	Style         string // 类型
+	Endpoint      string `gorm:"index"` // 接口类型 chat/embeddings/images...
 	UserAgent     string `gorm:"index"` // 用户代理
 	RemoteIP      string // 访问ip
 	AuthKeyID     uint   `gorm:"index"` // 使用的AuthKey ID
 	ChatIO        bool   // 是否开启IO记录
+	Strategy      string `gorm:"index"` // 使用的负载均衡策略
 
 	Error          string        // if status is error, this field will be set
 	Retry          int           // 重试次数
@@ -71,6 +79,8 @@ type ChatLog struct {
 	Tps            float64
 	Size           int // 响应大小 字节
 	Usage
+	Units float64 // 非 token 计量的用量，如图片张数
+	Unit  string  // Units 的单位
 }
 
 func (l ChatLog) WithError(err error) ChatLog {
@@ -103,6 +113,14 @@ type OutputUnion struct {
 	OfStringArray []string `gorm:"serializer:json"`
 }
 
+// ResponseConversation Responses 接口转换为 Chat 接口时保存的会话上下文，用于支持 previous_response_id
+type ResponseConversation struct {
+	gorm.Model
+	ResponseID         string `gorm:"uniqueIndex"`
+	PreviousResponseID string
+	Messages           string // 本轮输入与输出的 chat messages JSON
+}
+
 type ReqMeta struct {
 	UserAgent string // 用户代理
 	RemoteIP  string // 访问ip
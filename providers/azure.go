@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	DefaultAzureAPIVersion = "2024-10-21"
+	// 新版本 api-version 已移除部署列表接口
+	azureDeploymentsAPIVersion = "2022-12-01"
+)
+
+// Azure 调用 Azure OpenAI 部署，ProviderModel 即部署名称。
+// BaseURL 示例: https://{resource}.openai.azure.com
+type Azure struct {
+	BaseURL    string `json:"base_url"`
+	APIKey     string `json:"api_key"`
+	APIVersion string `json:"api_version"`
+	Proxy      string `json:"proxy,omitempty"` // HTTP or SOCKS5 proxy URL (e.g., http://proxy:8080 or socks5://proxy:1080)
+}
+
+func (a *Azure) BuildReq(ctx context.Context, header http.Header, model string, rawBody []byte) (*http.Request, error) {
+	body, err := setModel(header, rawBody, model)
+	if err != nil {
+		return nil, err
+	}
+	apiVersion := a.APIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAzureAPIVersion
+	}
+	endpoint := fmt.Sprintf("%s/openai/deployments/%s/%s?api-version=%s",
+		strings.TrimRight(a.BaseURL, "/"),
+		url.PathEscape(model),
+		openAIEndpointPath(ctx, "chat/completions"),
+		url.QueryEscape(apiVersion),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		req.Header = header
+	}
+	if !isMultipart(req.Header) {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("api-key", a.APIKey)
+
+	return req, nil
+}
+
+func (a *Azure) GetProxy() string {
+	return a.Proxy
+}
+
+type azureDeploymentList struct {
+	Data []azureDeployment `json:"data"`
+}
+
+type azureDeployment struct {
+	ID        string `json:"id"`
+	Model     string `json:"model"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Models 返回资源下的部署列表
+func (a *Azure) Models(ctx context.Context) ([]Model, error) {
+	endpoint := fmt.Sprintf("%s/openai/deployments?api-version=%s", strings.TrimRight(a.BaseURL, "/"), azureDeploymentsAPIVersion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("api-key", a.APIKey)
+	client := GetClientWithProxy(DefaultModelsTimeout, a.Proxy)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", res.StatusCode)
+	}
+
+	var deployments azureDeploymentList
+	if err := json.NewDecoder(res.Body).Decode(&deployments); err != nil {
+		return nil, err
+	}
+	models := make([]Model, 0, len(deployments.Data))
+	for _, deployment := range deployments.Data {
+		models = append(models, Model{
+			ID:      deployment.ID,
+			Object:  "model",
+			Created: deployment.CreatedAt,
+			OwnedBy: deployment.Model,
+		})
+	}
+	return models, nil
+}
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/atopos31/llmio/consts"
+)
+
+func TestAzureBuildReq(t *testing.T) {
+	azure := &Azure{BaseURL: "https://res.openai.azure.com/", APIKey: "key"}
+	tests := []struct {
+		name     string
+		endpoint consts.Endpoint
+		want     string
+	}{
+		{
+			name:     "chat",
+			endpoint: consts.EndpointChat,
+			want:     "https://res.openai.azure.com/openai/deployments/gpt-4o-prod/chat/completions?api-version=" + DefaultAzureAPIVersion,
+		},
+		{
+			name:     "embeddings",
+			endpoint: consts.EndpointEmbeddings,
+			want:     "https://res.openai.azure.com/openai/deployments/gpt-4o-prod/embeddings?api-version=" + DefaultAzureAPIVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), consts.ContextKeyEndpoint, tt.endpoint)
+			req, err := azure.BuildReq(ctx, http.Header{"Authorization": {"Bearer client"}}, "gpt-4o-prod", []byte(`{"model":"gpt-4o"}`))
+			if err != nil {
+				t.Fatalf("BuildReq() unexpected error: %v", err)
+			}
+			if got := req.URL.String(); got != tt.want {
+				t.Fatalf("url = %q, want %q", got, tt.want)
+			}
+			if got := req.Header.Get("api-key"); got != "key" {
+				t.Fatalf("api-key = %q", got)
+			}
+		})
+	}
+}
+
+func TestAzureModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments" || r.Header.Get("api-key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o-prod","model":"gpt-4o","created_at":1700000000,"object":"deployment"}]}`))
+	}))
+	defer server.Close()
+
+	models, err := (&Azure{BaseURL: server.URL, APIKey: "key"}).Models(context.Background())
+	if err != nil {
+		t.Fatalf("Models() unexpected error: %v", err)
+	}
+	if len(models) != 1 || models[0].ID != "gpt-4o-prod" || models[0].OwnedBy != "gpt-4o" {
+		t.Fatalf("unexpected models: %+v", models)
+	}
+}
+
+func TestStyleOf(t *testing.T) {
+	if got := StyleOf(consts.ProviderAzure); got != consts.StyleOpenAI {
+		t.Fatalf("StyleOf(azure) = %q", got)
+	}
+	if got := StyleOf(consts.StyleGemini); got != consts.StyleGemini {
+		t.Fatalf("StyleOf(gemini) = %q", got)
+	}
+	types := TypesOf(consts.StyleOpenAI)
+	if types[0] != consts.StyleOpenAI || !slices.Contains(types, consts.ProviderAzure) || slices.Contains(types, consts.ProviderBedrock) {
+		t.Fatalf("TypesOf(openai) = %v", types)
+	}
+}
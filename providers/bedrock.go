@@ -0,0 +1,202 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// Bedrock 通过 AWS Bedrock Runtime 调用 Anthropic 模型，请求使用 SigV4 签名。
+// ProviderModel 为 Bedrock 模型 ID 或推理配置文件 ID，如 us.anthropic.claude-sonnet-4-20250514-v1:0。
+// BaseURL 为空时使用 https://bedrock-runtime.{region}.amazonaws.com
+type Bedrock struct {
+	BaseURL         string `json:"base_url,omitempty"`
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token,omitempty"`
+	Proxy           string `json:"proxy,omitempty"` // HTTP or SOCKS5 proxy URL (e.g., http://proxy:8080 or socks5://proxy:1080)
+}
+
+func (b *Bedrock) BuildReq(ctx context.Context, header http.Header, model string, rawBody []byte) (*http.Request, error) {
+	// 模型与流式由路径决定，请求体不能携带
+	stream := gjson.GetBytes(rawBody, "stream").Bool()
+	body, err := sjson.DeleteBytes(rawBody, "model")
+	if err != nil {
+		return nil, err
+	}
+	if body, err = sjson.DeleteBytes(body, "stream"); err != nil {
+		return nil, err
+	}
+	if !gjson.GetBytes(body, "anthropic_version").Exists() {
+		if body, err = sjson.SetBytes(body, "anthropic_version", bedrockAnthropicVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	action := "invoke"
+	accept := "application/json"
+	if stream {
+		action = "invoke-with-response-stream"
+		accept = "application/vnd.amazon.eventstream"
+	}
+	baseURL := b.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", b.Region)
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/model/%s/%s", strings.TrimRight(baseURL, "/"), awsURIEncode(model), action),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		req.Header = header
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	// 需要读取明文的 event-stream 帧
+	req.Header.Del("Accept-Encoding")
+	signV4(req, body, b.credentials(), b.Region, "bedrock", time.Now())
+
+	return req, nil
+}
+
+func (b *Bedrock) GetProxy() string {
+	return b.Proxy
+}
+
+func (b *Bedrock) credentials() awsCredentials {
+	return awsCredentials{
+		AccessKeyID:     b.AccessKeyID,
+		SecretAccessKey: b.SecretAccessKey,
+		SessionToken:    b.SessionToken,
+	}
+}
+
+// DecodeResponse 将流式响应的 event-stream 帧还原为 Anthropic SSE，非流式响应本身即 Anthropic 格式
+func (b *Bedrock) DecodeResponse(res *http.Response) {
+	if !strings.Contains(res.Header.Get("Content-Type"), "application/vnd.amazon.eventstream") {
+		return
+	}
+	source := res.Body
+	pr, pw := io.Pipe()
+	go func() {
+		defer source.Close()
+		pw.CloseWithError(bedrockStreamToSSE(source, pw))
+	}()
+	res.Body = &decodedBody{PipeReader: pr, source: source}
+	res.Header.Del("Content-Length")
+	res.Header.Set("Content-Type", "text/event-stream")
+}
+
+type decodedBody struct {
+	*io.PipeReader
+	source io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	b.PipeReader.Close()
+	return b.source.Close()
+}
+
+// bedrockStreamToSSE chunk 事件的 bytes 字段即 base64 编码的 Anthropic 流式事件
+func bedrockStreamToSSE(r io.Reader, w io.Writer) error {
+	for {
+		message, err := readEventStreamMessage(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if messageType := message.Headers[":message-type"]; messageType != "event" {
+			errType := message.Headers[":exception-type"]
+			if errType == "" {
+				errType = message.Headers[":error-code"]
+			}
+			errMessage := gjson.GetBytes(message.Payload, "message").String()
+			if errMessage == "" {
+				errMessage = message.Headers[":error-message"]
+			}
+			data, _ := json.Marshal(map[string]any{
+				"type":  "error",
+				"error": map[string]string{"type": errType, "message": errMessage},
+			})
+			if _, err := fmt.Fprintf(w, "event: error\ndata: %s\n\n", data); err != nil {
+				return err
+			}
+			return fmt.Errorf("bedrock stream %s: %s: %s", messageType, errType, errMessage)
+		}
+		if message.Headers[":event-type"] != "chunk" {
+			continue
+		}
+
+		data, err := base64.StdEncoding.DecodeString(gjson.GetBytes(message.Payload, "bytes").String())
+		if err != nil {
+			return fmt.Errorf("decode bedrock chunk: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", gjson.GetBytes(data, "type").String(), data); err != nil {
+			return err
+		}
+	}
+}
+
+type bedrockFoundationModels struct {
+	ModelSummaries []struct {
+		ModelID      string `json:"modelId"`
+		ProviderName string `json:"providerName"`
+	} `json:"modelSummaries"`
+}
+
+// Models 返回区域内可用的 Anthropic 基础模型
+func (b *Bedrock) Models(ctx context.Context) ([]Model, error) {
+	baseURL := b.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://bedrock.%s.amazonaws.com", b.Region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/foundation-models?byProvider=anthropic", strings.TrimRight(baseURL, "/")), nil)
+	if err != nil {
+		return nil, err
+	}
+	signV4(req, nil, b.credentials(), b.Region, "bedrock", time.Now())
+	client := GetClientWithProxy(DefaultModelsTimeout, b.Proxy)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", res.StatusCode)
+	}
+	var foundationModels bedrockFoundationModels
+	if err := json.NewDecoder(res.Body).Decode(&foundationModels); err != nil {
+		return nil, err
+	}
+	models := make([]Model, 0, len(foundationModels.ModelSummaries))
+	for _, model := range foundationModels.ModelSummaries {
+		models = append(models, Model{
+			ID:      model.ModelID,
+			Object:  "model",
+			OwnedBy: model.ProviderName,
+		})
+	}
+	return models, nil
+}
@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// encodeEventStreamMessage 按 AWS event-stream 格式编码一条只含字符串头的消息
+func encodeEventStreamMessage(headers [][2]string, payload []byte) []byte {
+	var headerBuf bytes.Buffer
+	for _, header := range headers {
+		headerBuf.WriteByte(byte(len(header[0])))
+		headerBuf.WriteString(header[0])
+		headerBuf.WriteByte(7)
+		binary.Write(&headerBuf, binary.BigEndian, uint16(len(header[1])))
+		headerBuf.WriteString(header[1])
+	}
+	totalLen := uint32(eventStreamPreludeLen + headerBuf.Len() + len(payload) + 4)
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, totalLen)
+	binary.Write(&buf, binary.BigEndian, uint32(headerBuf.Len()))
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	buf.Write(headerBuf.Bytes())
+	buf.Write(payload)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes()
+}
+
+func bedrockChunk(event string) []byte {
+	payload := `{"bytes":"` + base64.StdEncoding.EncodeToString([]byte(event)) + `"}`
+	return encodeEventStreamMessage([][2]string{
+		{":message-type", "event"},
+		{":event-type", "chunk"},
+		{":content-type", "application/json"},
+	}, []byte(payload))
+}
+
+func TestSignV4(t *testing.T) {
+	// AWS 文档中的签名示例
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(req, nil, awsCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "iam", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestBedrockStream(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.EscapedPath(), r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		w.Write(bedrockChunk(`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":10,"output_tokens":1}}}`))
+		w.Write(bedrockChunk(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`))
+		w.Write(bedrockChunk(`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`))
+		w.Write(bedrockChunk(`{"type":"message_stop"}`))
+	}))
+	defer server.Close()
+
+	bedrock := &Bedrock{BaseURL: server.URL, Region: "us-east-1", AccessKeyID: "AK", SecretAccessKey: "SK"}
+	req, err := bedrock.BuildReq(context.Background(), http.Header{}, "us.anthropic.claude-sonnet-4-20250514-v1:0", []byte(`{"model":"claude","stream":true,"max_tokens":16,"messages":[]}`))
+	if err != nil {
+		t.Fatalf("BuildReq() unexpected error: %v", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() unexpected error: %v", err)
+	}
+	bedrock.DecodeResponse(res)
+	out, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("read body unexpected error: %v", err)
+	}
+
+	if gotPath != "/model/us.anthropic.claude-sonnet-4-20250514-v1%3A0/invoke-with-response-stream" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AK/") || !strings.Contains(gotAuth, "/us-east-1/bedrock/aws4_request") {
+		t.Errorf("authorization = %q", gotAuth)
+	}
+	body := gjson.ParseBytes(gotBody)
+	if body.Get("model").Exists() || body.Get("stream").Exists() || body.Get("anthropic_version").String() != bedrockAnthropicVersion {
+		t.Errorf("unexpected body: %s", gotBody)
+	}
+	if got := res.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("content type = %q", got)
+	}
+	if !strings.HasPrefix(string(out), "event: message_start\ndata: {\"type\":\"message_start\"") ||
+		!strings.Contains(string(out), "event: content_block_delta\n") ||
+		!strings.HasSuffix(string(out), "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n") {
+		t.Errorf("unexpected sse:\n%s", out)
+	}
+}
+
+func TestBedrockStreamException(t *testing.T) {
+	stream := append(bedrockChunk(`{"type":"message_start","message":{}}`), encodeEventStreamMessage([][2]string{
+		{":message-type", "exception"},
+		{":exception-type", "throttlingException"},
+	}, []byte(`{"message":"Too many requests"}`))...)
+
+	var out strings.Builder
+	if err := bedrockStreamToSSE(bytes.NewReader(stream), &out); err == nil {
+		t.Fatal("bedrockStreamToSSE() should fail on exception")
+	}
+	if !strings.Contains(out.String(), `event: error`) || !strings.Contains(out.String(), `"type":"throttlingException"`) {
+		t.Errorf("unexpected sse:\n%s", out.String())
+	}
+
+	corrupted := bedrockChunk(`{"type":"message_stop"}`)
+	corrupted[len(corrupted)-1] ^= 0xff
+	if err := bedrockStreamToSSE(bytes.NewReader(corrupted), io.Discard); err == nil {
+		t.Fatal("bedrockStreamToSSE() should fail on checksum mismatch")
+	}
+}
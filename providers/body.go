@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/sjson"
+)
+
+func isMultipart(header http.Header) bool {
+	return strings.HasPrefix(strings.ToLower(header.Get("Content-Type")), "multipart/form-data")
+}
+
+// setModel 替换请求体中的 model 字段，支持 JSON 与 multipart/form-data
+func setModel(header http.Header, body []byte, model string) ([]byte, error) {
+	if header == nil || !isMultipart(header) {
+		return sjson.SetBytes(body, "model", model)
+	}
+	_, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("multipart boundary is empty")
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	// 沿用原 boundary，Content-Type 无需改变
+	if err := writer.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		dst, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "model" && part.FileName() == "" {
+			_, err = io.WriteString(dst, model)
+		} else {
+			_, err = io.Copy(dst, part)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
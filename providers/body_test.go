@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestSetModel(t *testing.T) {
+	body, err := setModel(http.Header{}, []byte(`{"model":"alias","prompt":"cat"}`), "gpt-image-1")
+	if err != nil {
+		t.Fatalf("setModel() unexpected error: %v", err)
+	}
+	if got := gjson.GetBytes(body, "model").String(); got != "gpt-image-1" {
+		t.Fatalf("json model = %q", got)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("model", "alias")
+	file, _ := writer.CreateFormFile("image", "cat.png")
+	file.Write([]byte("\x89PNG"))
+	writer.WriteField("prompt", "add a hat")
+	writer.Close()
+
+	header := http.Header{}
+	header.Set("Content-Type", writer.FormDataContentType())
+	body, err = setModel(header, buf.Bytes(), "gpt-image-1")
+	if err != nil {
+		t.Fatalf("setModel() unexpected error: %v", err)
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), writer.Boundary())
+	fields := map[string]string{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() unexpected error: %v", err)
+		}
+		value, _ := io.ReadAll(part)
+		fields[part.FormName()] = string(value)
+	}
+	want := map[string]string{"model": "gpt-image-1", "image": "\x89PNG", "prompt": "add a hat"}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %s = %q, want %q", k, fields[k], v)
+		}
+	}
+}
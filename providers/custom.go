@@ -0,0 +1,240 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Custom 按配置中的路径模板与 gjson/sjson 映射规则对接非标准接口，对外表现为 OpenAI 协议。
+// path 与 headers 中的 {model}、{api_key} 会被替换。
+type Custom struct {
+	BaseURL        string            `json:"base_url"`
+	APIKey         string            `json:"api_key"`
+	Path           string            `json:"path"`
+	StreamPath     string            `json:"stream_path,omitempty"` // 为空时使用 path
+	Headers        map[string]string `json:"headers,omitempty"`
+	Request        CustomMapping     `json:"request"`
+	Response       CustomMapping     `json:"response"`
+	StreamResponse CustomMapping     `json:"stream_response"`
+	StreamFormat   string            `json:"stream_format,omitempty"` // sse 或 ndjson，默认 sse
+	ModelsPath     string            `json:"models_path,omitempty"`
+	ModelsIDPath   string            `json:"models_id_path,omitempty"` // 如 data.#.id
+	Proxy          string            `json:"proxy,omitempty"`          // HTTP or SOCKS5 proxy URL (e.g., http://proxy:8080 or socks5://proxy:1080)
+
+	stream bool
+}
+
+// CustomMapping 一组 JSON 映射规则，未配置任何规则时原样透传
+type CustomMapping struct {
+	Passthrough bool         `json:"passthrough,omitempty"` // 以源 JSON 为基础修改，否则从空对象开始构建
+	Set         []CustomRule `json:"set,omitempty"`
+	Delete      []string     `json:"delete,omitempty"` // 按 sjson 路径删除
+}
+
+// CustomRule 将源 JSON 中 from 路径的值或常量 value 写入目标的 path
+type CustomRule struct {
+	Path  string          `json:"path"`            // sjson 目标路径
+	From  string          `json:"from,omitempty"`  // gjson 源路径，源中不存在时跳过
+	Value json.RawMessage `json:"value,omitempty"` // 常量值
+}
+
+func (m CustomMapping) empty() bool {
+	return !m.Passthrough && len(m.Set) == 0 && len(m.Delete) == 0
+}
+
+// Apply 按规则将 src 映射为新的 JSON
+func (m CustomMapping) Apply(src []byte) ([]byte, error) {
+	if m.empty() {
+		return src, nil
+	}
+	dst := []byte("{}")
+	if m.Passthrough {
+		dst = bytes.Clone(src)
+	}
+	var err error
+	for _, rule := range m.Set {
+		raw := []byte(rule.Value)
+		if rule.From != "" {
+			value := gjson.GetBytes(src, rule.From)
+			if !value.Exists() {
+				continue
+			}
+			raw = []byte(value.Raw)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		if dst, err = sjson.SetRawBytes(dst, rule.Path, raw); err != nil {
+			return nil, fmt.Errorf("set %s: %w", rule.Path, err)
+		}
+	}
+	for _, path := range m.Delete {
+		if dst, err = sjson.DeleteBytes(dst, path); err != nil {
+			return nil, fmt.Errorf("delete %s: %w", path, err)
+		}
+	}
+	return dst, nil
+}
+
+func (c *Custom) render(s, model string) string {
+	return strings.NewReplacer("{model}", model, "{api_key}", c.APIKey).Replace(s)
+}
+
+func (c *Custom) BuildReq(ctx context.Context, header http.Header, model string, rawBody []byte) (*http.Request, error) {
+	if endpoint := endpointFromContext(ctx); endpoint != consts.EndpointChat {
+		return nil, fmt.Errorf("custom provider does not support endpoint: %s", endpoint)
+	}
+	body, err := sjson.SetBytes(rawBody, "model", model)
+	if err != nil {
+		return nil, err
+	}
+	c.stream = gjson.GetBytes(body, "stream").Bool()
+	if body, err = c.Request.Apply(body); err != nil {
+		return nil, err
+	}
+
+	path := c.Path
+	if c.stream && c.StreamPath != "" {
+		path = c.StreamPath
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.BaseURL, "/")+c.render(path, model), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		req.Header = header
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(c.Headers) == 0 {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	for key, value := range c.Headers {
+		req.Header.Set(key, c.render(value, model))
+	}
+	if !c.Response.empty() || !c.StreamResponse.empty() {
+		// 需要读取明文响应
+		req.Header.Del("Accept-Encoding")
+	}
+	return req, nil
+}
+
+func (c *Custom) GetProxy() string {
+	return c.Proxy
+}
+
+// DecodeResponse 按响应映射规则将提供商响应转换为 OpenAI 格式
+func (c *Custom) DecodeResponse(res *http.Response) {
+	if !c.stream && c.Response.empty() {
+		return
+	}
+	if c.stream && c.StreamResponse.empty() && c.StreamFormat != "ndjson" {
+		return
+	}
+	source := res.Body
+	pr, pw := io.Pipe()
+	go func() {
+		defer source.Close()
+		if c.stream {
+			pw.CloseWithError(c.mapStream(source, pw))
+			return
+		}
+		data, err := io.ReadAll(source)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		out, err := c.Response.Apply(data)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		_, err = pw.Write(out)
+		pw.CloseWithError(err)
+	}()
+	res.Body = &decodedBody{PipeReader: pr, source: source}
+	res.Header.Del("Content-Length")
+	res.Header.Del("Content-Encoding")
+	if c.stream {
+		res.Header.Set("Content-Type", "text/event-stream")
+	} else {
+		res.Header.Set("Content-Type", "application/json")
+	}
+}
+
+// mapStream 逐行读取 SSE 的 data 或 NDJSON，映射后输出为 OpenAI SSE
+func (c *Custom) mapStream(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 8*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if c.StreamFormat != "ndjson" {
+			data, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				continue
+			}
+			line = strings.TrimSpace(data)
+		}
+		if line == "" || line == "[DONE]" {
+			continue
+		}
+		out, err := c.StreamResponse.Apply([]byte(line))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", out); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "data: [DONE]\n\n")
+	return err
+}
+
+// Models 配置了 models_path 与 models_id_path 时返回模型 ID 列表
+func (c *Custom) Models(ctx context.Context) ([]Model, error) {
+	if c.ModelsPath == "" || c.ModelsIDPath == "" {
+		return nil, errors.New("models_path and models_id_path are not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.BaseURL, "/")+c.ModelsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Headers) == 0 {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	for key, value := range c.Headers {
+		req.Header.Set(key, c.render(value, ""))
+	}
+	client := GetClientWithProxy(DefaultModelsTimeout, c.Proxy)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", res.StatusCode)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	var models []Model
+	for _, id := range gjson.GetBytes(body, c.ModelsIDPath).Array() {
+		models = append(models, Model{ID: id.String(), Object: "model", OwnedBy: "custom"})
+	}
+	return models, nil
+}
@@ -0,0 +1,134 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const customTestConfig = `{
+	"base_url": "%s",
+	"api_key": "secret",
+	"path": "/generate/{model}",
+	"stream_path": "/generate/{model}/stream",
+	"headers": {"X-Api-Key": "{api_key}"},
+	"request": {
+		"set": [
+			{"path": "prompt_messages", "from": "messages"},
+			{"path": "params.max_new_tokens", "from": "max_tokens"},
+			{"path": "params.top_k", "value": 40}
+		]
+	},
+	"response": {
+		"set": [
+			{"path": "object", "value": "chat.completion"},
+			{"path": "choices.0.message.content", "from": "output.text"},
+			{"path": "usage.total_tokens", "from": "usage.total"}
+		]
+	},
+	"stream_format": "ndjson",
+	"stream_response": {
+		"set": [
+			{"path": "choices.0.delta.content", "from": "output.text"},
+			{"path": "usage.total_tokens", "from": "usage.total"}
+		]
+	}
+}`
+
+func TestCustomMappingApply(t *testing.T) {
+	mapping := CustomMapping{
+		Passthrough: true,
+		Set:         []CustomRule{{Path: "input", From: "messages.#.content"}, {Path: "missing", From: "nope"}},
+		Delete:      []string{"messages"},
+	}
+	out, err := mapping.Apply([]byte(`{"model":"m","messages":[{"content":"a"},{"content":"b"}]}`))
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if got := string(out); got != `{"model":"m","input":["a","b"]}` {
+		t.Fatalf("Apply() = %s", got)
+	}
+
+	in := []byte(`{"a":1}`)
+	if out, _ := (CustomMapping{}).Apply(in); string(out) != string(in) {
+		t.Fatalf("empty mapping should pass through, got %s", out)
+	}
+}
+
+func TestCustomProvider(t *testing.T) {
+	var gotPath, gotKey string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotKey = r.URL.Path, r.Header.Get("X-Api-Key")
+		gotBody, _ = io.ReadAll(r.Body)
+		if strings.HasSuffix(r.URL.Path, "/stream") {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Write([]byte("{\"output\":{\"text\":\"He\"}}\n{\"output\":{\"text\":\"y\"},\"usage\":{\"total\":9}}\n"))
+			return
+		}
+		w.Write([]byte(`{"output":{"text":"Hey"},"usage":{"total":9}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantPath string
+		want     func(t *testing.T, out string)
+	}{
+		{
+			name:     "non stream",
+			body:     `{"model":"alias","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`,
+			wantPath: "/generate/vendor-7b",
+			want: func(t *testing.T, out string) {
+				if gjson.Get(out, "choices.0.message.content").String() != "Hey" || gjson.Get(out, "usage.total_tokens").Int() != 9 {
+					t.Errorf("unexpected response: %s", out)
+				}
+			},
+		},
+		{
+			name:     "ndjson stream",
+			body:     `{"model":"alias","stream":true,"messages":[{"role":"user","content":"hi"}]}`,
+			wantPath: "/generate/vendor-7b/stream",
+			want: func(t *testing.T, out string) {
+				want := "data: {\"choices\":[{\"delta\":{\"content\":\"He\"}}]}\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"y\"}}],\"usage\":{\"total_tokens\":9}}\n\ndata: [DONE]\n\n"
+				if out != want {
+					t.Errorf("stream = %q, want %q", out, want)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New("custom", strings.Replace(customTestConfig, "%s", server.URL, 1), "")
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			req, err := provider.BuildReq(context.Background(), http.Header{}, "vendor-7b", []byte(tt.body))
+			if err != nil {
+				t.Fatalf("BuildReq() unexpected error: %v", err)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() unexpected error: %v", err)
+			}
+			provider.(ResponseDecoder).DecodeResponse(res)
+			out, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+
+			if gotPath != tt.wantPath || gotKey != "secret" {
+				t.Errorf("path = %q, key = %q", gotPath, gotKey)
+			}
+			body := gjson.ParseBytes(gotBody)
+			if body.Get("prompt_messages.0.content").String() != "hi" || body.Get("params.top_k").Int() != 40 || body.Get("model").Exists() {
+				t.Errorf("unexpected request body: %s", gotBody)
+			}
+			tt.want(t, string(out))
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	// total length + headers length + prelude crc
+	eventStreamPreludeLen = 12
+	eventStreamMaxMessage = 16 << 20
+)
+
+// eventStreamMessage AWS event-stream 二进制帧中的一条消息
+type eventStreamMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// readEventStreamMessage 读取一条 application/vnd.amazon.eventstream 消息并校验 CRC
+func readEventStreamMessage(r io.Reader) (*eventStreamMessage, error) {
+	prelude := make([]byte, eventStreamPreludeLen)
+	if _, err := io.ReadFull(r, prelude); err != nil {
+		return nil, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[0:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, errors.New("event stream prelude checksum mismatch")
+	}
+	if totalLen > eventStreamMaxMessage || totalLen < eventStreamPreludeLen+headersLen+4 {
+		return nil, fmt.Errorf("invalid event stream message length: %d", totalLen)
+	}
+
+	message := make([]byte, totalLen)
+	copy(message, prelude)
+	if _, err := io.ReadFull(r, message[eventStreamPreludeLen:]); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	crcOffset := totalLen - 4
+	if crc32.ChecksumIEEE(message[:crcOffset]) != binary.BigEndian.Uint32(message[crcOffset:]) {
+		return nil, errors.New("event stream message checksum mismatch")
+	}
+
+	headers, err := parseEventStreamHeaders(message[eventStreamPreludeLen : eventStreamPreludeLen+headersLen])
+	if err != nil {
+		return nil, err
+	}
+	return &eventStreamMessage{
+		Headers: headers,
+		Payload: message[eventStreamPreludeLen+headersLen : crcOffset],
+	}, nil
+}
+
+// parseEventStreamHeaders 解析消息头，只保留字符串类型的值
+func parseEventStreamHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(data) > 0 {
+		nameLen := int(data[0])
+		if len(data) < 1+nameLen+1 {
+			return nil, errors.New("truncated event stream header")
+		}
+		name := string(data[1 : 1+nameLen])
+		valueType := data[1+nameLen]
+		data = data[2+nameLen:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // bool true / false
+		case 2: // byte
+			size = 1
+		case 3: // int16
+			size = 2
+		case 4: // int32
+			size = 4
+		case 5, 8: // int64 / timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // bytes / string
+			if len(data) < 2 {
+				return nil, errors.New("truncated event stream header")
+			}
+			size = int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+			if len(data) < size {
+				return nil, errors.New("truncated event stream header")
+			}
+			if valueType == 7 {
+				headers[name] = string(data[:size])
+			}
+		default:
+			return nil, fmt.Errorf("unknown event stream header type: %d", valueType)
+		}
+		if len(data) < size {
+			return nil, errors.New("truncated event stream header")
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
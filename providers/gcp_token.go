@@ -0,0 +1,153 @@
+package providers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultGoogleTokenURL = "https://oauth2.googleapis.com/token"
+	googleCloudScope      = "https://www.googleapis.com/auth/cloud-platform"
+	// 令牌到期前提前刷新
+	googleTokenRefreshSkew = time.Minute
+)
+
+// googleServiceAccount 服务账号密钥 JSON 中用到的字段
+type googleServiceAccount struct {
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+type googleToken struct {
+	AccessToken string
+	Expiry      time.Time
+}
+
+type googleTokenKey struct {
+	clientEmail  string
+	privateKeyID string
+	tokenURL     string
+}
+
+// googleTokens 进程内缓存的访问令牌，按服务账号与令牌地址区分
+var googleTokens = struct {
+	sync.Mutex
+	tokens map[googleTokenKey]googleToken
+}{tokens: make(map[googleTokenKey]googleToken)}
+
+// googleAccessToken 返回缓存的访问令牌，过期时通过 JWT 换取新令牌
+func googleAccessToken(ctx context.Context, account googleServiceAccount, tokenURL, proxy string) (string, error) {
+	key := googleTokenKey{clientEmail: account.ClientEmail, privateKeyID: account.PrivateKeyID, tokenURL: tokenURL}
+	// 持锁换取令牌，避免并发请求重复换取
+	googleTokens.Lock()
+	defer googleTokens.Unlock()
+	if token, ok := googleTokens.tokens[key]; ok && time.Until(token.Expiry) > googleTokenRefreshSkew {
+		return token.AccessToken, nil
+	}
+
+	token, err := exchangeGoogleJWT(ctx, account, tokenURL, proxy, time.Now())
+	if err != nil {
+		return "", err
+	}
+	googleTokens.tokens[key] = *token
+	return token.AccessToken, nil
+}
+
+func exchangeGoogleJWT(ctx context.Context, account googleServiceAccount, tokenURL, proxy string, now time.Time) (*googleToken, error) {
+	assertion, err := signGoogleJWT(account, tokenURL, now)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := GetClientWithProxy(DefaultModelsTimeout, proxy)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("token exchange status: %d, body: %s", res.StatusCode, string(body))
+	}
+	var tokenRes struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tokenRes); err != nil {
+		return nil, err
+	}
+	if tokenRes.AccessToken == "" {
+		return nil, errors.New("token exchange returned empty access token")
+	}
+	return &googleToken{
+		AccessToken: tokenRes.AccessToken,
+		Expiry:      now.Add(time.Duration(tokenRes.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// signGoogleJWT 使用服务账号私钥生成 RS256 JWT 断言
+func signGoogleJWT(account googleServiceAccount, audience string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return "", errors.New("invalid service account private key")
+	}
+	var key *rsa.PrivateKey
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return "", errors.New("service account private key is not RSA")
+		}
+		key = rsaKey
+	} else if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("parse service account private key: %w", err)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": account.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   account.ClientEmail,
+		"scope": googleCloudScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -9,11 +9,14 @@ import (
 	"strings"
 
 	"github.com/atopos31/llmio/consts"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
 // Gemini 调用 Gemini 原生 REST API。
 // BaseURL 推荐: https://generativelanguage.googleapis.com/v1beta
-// 通过 POST /models/{model}:generateContent 进行内容生成。
+// 通过 POST /models/{model}:generateContent 进行内容生成，
+// :embedContent / :batchEmbedContents 生成向量。
 type Gemini struct {
 	BaseURL string `json:"base_url"`
 	APIKey  string `json:"api_key"`
@@ -25,9 +28,26 @@ func (g *Gemini) BuildReq(ctx context.Context, header http.Header, model string,
 	stream, _ := ctx.Value(consts.ContextKeyGeminiStream).(bool)
 	action := "generateContent"
 	urlSuffix := ""
-	if stream {
-		action = "streamGenerateContent"
-		urlSuffix = "?alt=sse"
+	switch endpointFromContext(ctx) {
+	case consts.EndpointEmbeddings:
+		action = "embedContent"
+		stream = false
+	case consts.EndpointBatchEmbeddings:
+		action = "batchEmbedContents"
+		stream = false
+		// 批量请求中每一项的 model 需与路径中的模型一致
+		for i := range gjson.GetBytes(rawBody, "requests.#").Int() {
+			body, err := sjson.SetBytes(rawBody, fmt.Sprintf("requests.%d.model", i), "models/"+model)
+			if err != nil {
+				return nil, err
+			}
+			rawBody = body
+		}
+	default:
+		if stream {
+			action = "streamGenerateContent"
+			urlSuffix = "?alt=sse"
+		}
 	}
 
 	req, err := http.NewRequestWithContext(
@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tidwall/sjson"
+)
+
+// Ollama 调用 Ollama 原生接口，流式响应为 NDJSON。
+// BaseURL 示例: http://localhost:11434
+type Ollama struct {
+	BaseURL string `json:"base_url"`
+	APIKey  string `json:"api_key,omitempty"` // 本地部署无需设置
+	Proxy   string `json:"proxy,omitempty"`   // HTTP or SOCKS5 proxy URL (e.g., http://proxy:8080 or socks5://proxy:1080)
+}
+
+func (o *Ollama) BuildReq(ctx context.Context, header http.Header, model string, rawBody []byte) (*http.Request, error) {
+	body, err := sjson.SetBytes(rawBody, "model", model)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/chat", strings.TrimRight(o.BaseURL, "/")), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		req.Header = header
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+	return req, nil
+}
+
+func (o *Ollama) GetProxy() string {
+	return o.Proxy
+}
+
+type ollamaTags struct {
+	Models []struct {
+		Name       string    `json:"name"`
+		ModifiedAt time.Time `json:"modified_at"`
+	} `json:"models"`
+}
+
+func (o *Ollama) Models(ctx context.Context) ([]Model, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/tags", strings.TrimRight(o.BaseURL, "/")), nil)
+	if err != nil {
+		return nil, err
+	}
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+	client := GetClientWithProxy(DefaultModelsTimeout, o.Proxy)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", res.StatusCode)
+	}
+	var tags ollamaTags
+	if err := json.NewDecoder(res.Body).Decode(&tags); err != nil {
+		return nil, err
+	}
+	models := make([]Model, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		models = append(models, Model{
+			ID:      model.Name,
+			Object:  "model",
+			Created: model.ModifiedAt.Unix(),
+			OwnedBy: "ollama",
+		})
+	}
+	return models, nil
+}
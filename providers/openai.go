@@ -6,8 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-
-	"github.com/tidwall/sjson"
 )
 
 type OpenAI struct {
@@ -17,18 +15,20 @@ type OpenAI struct {
 }
 
 func (o *OpenAI) BuildReq(ctx context.Context, header http.Header, model string, rawBody []byte) (*http.Request, error) {
-	body, err := sjson.SetBytes(rawBody, "model", model)
+	body, err := setModel(header, rawBody, model)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/chat/completions", o.BaseURL), bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", o.BaseURL, openAIEndpointPath(ctx, "chat/completions")), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	if header != nil {
 		req.Header = header
 	}
-	req.Header.Set("Content-Type", "application/json")
+	if !isMultipart(req.Header) {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.APIKey))
 
 	return req, nil
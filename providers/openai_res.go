@@ -22,7 +22,7 @@ func (o *OpenAIRes) BuildReq(ctx context.Context, header http.Header, model stri
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/responses", o.BaseURL), bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", o.BaseURL, openAIEndpointPath(ctx, "responses")), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
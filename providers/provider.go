@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"maps"
 	"net/http"
+	"slices"
 
 	"github.com/atopos31/llmio/consts"
 )
@@ -27,6 +29,66 @@ type Provider interface {
 	GetProxy() string // Returns the proxy URL if configured
 }
 
+// ResponseDecoder 响应格式与其协议不一致的提供商实现，将成功响应还原为协议格式
+type ResponseDecoder interface {
+	DecodeResponse(res *http.Response)
+}
+
+// providerStyles 提供商类型与其兼容的接口协议，未列出的类型即协议本身
+var providerStyles = map[string]consts.Style{
+	consts.ProviderAzure:           consts.StyleOpenAI,
+	consts.ProviderBedrock:         consts.StyleAnthropic,
+	consts.ProviderVertex:          consts.StyleGemini,
+	consts.ProviderVertexAnthropic: consts.StyleAnthropic,
+	consts.ProviderCustom:          consts.StyleOpenAI,
+}
+
+// StyleOf 返回提供商类型使用的接口协议
+func StyleOf(providerType string) consts.Style {
+	if style, ok := providerStyles[providerType]; ok {
+		return style
+	}
+	return providerType
+}
+
+// TypesOf 返回使用给定接口协议的全部提供商类型
+func TypesOf(styles ...consts.Style) []string {
+	types := slices.Clone(styles)
+	for _, providerType := range slices.Sorted(maps.Keys(providerStyles)) {
+		if slices.Contains(styles, providerStyles[providerType]) {
+			types = append(types, providerType)
+		}
+	}
+	return types
+}
+
+// openAIEndpointPaths chat 以外接口在 OpenAI 兼容提供商下的请求路径
+var openAIEndpointPaths = map[consts.Endpoint]string{
+	consts.EndpointCompletions:         "completions",
+	consts.EndpointEmbeddings:          "embeddings",
+	consts.EndpointImageGenerations:    "images/generations",
+	consts.EndpointImageEdits:          "images/edits",
+	consts.EndpointAudioTranscriptions: "audio/transcriptions",
+	consts.EndpointAudioSpeech:         "audio/speech",
+	consts.EndpointRerank:              "rerank",
+}
+
+func endpointFromContext(ctx context.Context) consts.Endpoint {
+	endpoint, ok := ctx.Value(consts.ContextKeyEndpoint).(consts.Endpoint)
+	if !ok {
+		return consts.EndpointChat
+	}
+	return endpoint
+}
+
+// openAIEndpointPath 返回 ctx 中接口类型对应的路径，chat 接口返回 defaultPath
+func openAIEndpointPath(ctx context.Context, defaultPath string) string {
+	if path, ok := openAIEndpointPaths[endpointFromContext(ctx)]; ok {
+		return path
+	}
+	return defaultPath
+}
+
 func New(Type, providerConfig, proxy string) (Provider, error) {
 	switch Type {
 	case consts.StyleOpenAI:
@@ -57,6 +119,45 @@ func New(Type, providerConfig, proxy string) (Provider, error) {
 		}
 		gemini.Proxy = proxy
 		return &gemini, nil
+	case consts.StyleOllama:
+		var ollama Ollama
+		if err := json.Unmarshal([]byte(providerConfig), &ollama); err != nil {
+			return nil, errors.New("invalid ollama config")
+		}
+		ollama.Proxy = proxy
+		return &ollama, nil
+	case consts.ProviderAzure:
+		var azure Azure
+		if err := json.Unmarshal([]byte(providerConfig), &azure); err != nil {
+			return nil, errors.New("invalid azure config")
+		}
+		azure.Proxy = proxy
+		return &azure, nil
+	case consts.ProviderBedrock:
+		var bedrock Bedrock
+		if err := json.Unmarshal([]byte(providerConfig), &bedrock); err != nil {
+			return nil, errors.New("invalid bedrock config")
+		}
+		bedrock.Proxy = proxy
+		return &bedrock, nil
+	case consts.ProviderVertex, consts.ProviderVertexAnthropic:
+		var vertex Vertex
+		if err := json.Unmarshal([]byte(providerConfig), &vertex); err != nil {
+			return nil, errors.New("invalid vertex config")
+		}
+		vertex.publisher = vertexPublisherGoogle
+		if Type == consts.ProviderVertexAnthropic {
+			vertex.publisher = vertexPublisherAnthropic
+		}
+		vertex.Proxy = proxy
+		return &vertex, nil
+	case consts.ProviderCustom:
+		var custom Custom
+		if err := json.Unmarshal([]byte(providerConfig), &custom); err != nil {
+			return nil, errors.New("invalid custom config")
+		}
+		custom.Proxy = proxy
+		return &custom, nil
 	default:
 		return nil, errors.New("unknown provider")
 	}
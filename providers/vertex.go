@@ -0,0 +1,204 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	vertexPublisherGoogle    = "google"
+	vertexPublisherAnthropic = "anthropic"
+	vertexAnthropicVersion   = "vertex-2023-10-16"
+)
+
+// Vertex 通过 Vertex AI 调用 Gemini（vertex）或 Anthropic（vertex-anthropic）模型，
+// 使用服务账号换取的 OAuth 访问令牌鉴权。
+// BaseURL 为空时使用 https://{region}-aiplatform.googleapis.com，region 为 global 时使用 https://aiplatform.googleapis.com
+type Vertex struct {
+	BaseURL   string `json:"base_url,omitempty"`
+	ProjectID string `json:"project_id,omitempty"` // 为空时使用服务账号所属项目
+	Region    string `json:"region"`
+	TokenURL  string `json:"token_url,omitempty"` // 为空时使用服务账号中的 token_uri
+	// 服务账号密钥，可为 JSON 对象或 JSON 字符串
+	ServiceAccount json.RawMessage `json:"service_account"`
+	Proxy          string          `json:"proxy,omitempty"` // HTTP or SOCKS5 proxy URL (e.g., http://proxy:8080 or socks5://proxy:1080)
+
+	publisher string
+}
+
+func (v *Vertex) BuildReq(ctx context.Context, header http.Header, model string, rawBody []byte) (*http.Request, error) {
+	account, err := v.serviceAccount()
+	if err != nil {
+		return nil, err
+	}
+	if endpoint := endpointFromContext(ctx); endpoint != consts.EndpointChat {
+		return nil, fmt.Errorf("vertex provider does not support endpoint: %s", endpoint)
+	}
+
+	var action, urlSuffix string
+	body := rawBody
+	switch v.publisher {
+	case vertexPublisherAnthropic:
+		// 模型由路径决定，stream 保留在请求体中
+		action = "rawPredict"
+		if gjson.GetBytes(body, "stream").Bool() {
+			action = "streamRawPredict"
+		}
+		if body, err = sjson.DeleteBytes(body, "model"); err != nil {
+			return nil, err
+		}
+		if !gjson.GetBytes(body, "anthropic_version").Exists() {
+			if body, err = sjson.SetBytes(body, "anthropic_version", vertexAnthropicVersion); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		model = strings.TrimPrefix(model, "models/")
+		action = "generateContent"
+		if stream, _ := ctx.Value(consts.ContextKeyGeminiStream).(bool); stream {
+			action = "streamGenerateContent"
+			urlSuffix = "?alt=sse"
+		}
+	}
+
+	token, err := googleAccessToken(ctx, account, v.tokenURL(account), v.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/%s/models/%s:%s%s",
+			v.baseURL(), v.projectID(account), v.Region, v.publisher, url.PathEscape(model), action, urlSuffix),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		req.Header = header
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req, nil
+}
+
+func (v *Vertex) GetProxy() string {
+	return v.Proxy
+}
+
+func (v *Vertex) serviceAccount() (googleServiceAccount, error) {
+	var account googleServiceAccount
+	raw := v.ServiceAccount
+	var encoded string
+	if json.Unmarshal(raw, &encoded) == nil {
+		raw = []byte(encoded)
+	}
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return account, fmt.Errorf("invalid service account: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return account, errors.New("service account requires client_email and private_key")
+	}
+	return account, nil
+}
+
+func (v *Vertex) tokenURL(account googleServiceAccount) string {
+	if v.TokenURL != "" {
+		return v.TokenURL
+	}
+	if account.TokenURI != "" {
+		return account.TokenURI
+	}
+	return DefaultGoogleTokenURL
+}
+
+func (v *Vertex) projectID(account googleServiceAccount) string {
+	if v.ProjectID != "" {
+		return v.ProjectID
+	}
+	return account.ProjectID
+}
+
+func (v *Vertex) baseURL() string {
+	if v.BaseURL != "" {
+		return strings.TrimRight(v.BaseURL, "/")
+	}
+	if v.Region == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com", v.Region)
+}
+
+type vertexPublisherModels struct {
+	PublisherModels []struct {
+		Name string `json:"name"`
+	} `json:"publisherModels"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// Models 返回发布方在 Model Garden 中的模型
+func (v *Vertex) Models(ctx context.Context) ([]Model, error) {
+	account, err := v.serviceAccount()
+	if err != nil {
+		return nil, err
+	}
+	token, err := googleAccessToken(ctx, account, v.tokenURL(account), v.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	client := GetClientWithProxy(DefaultModelsTimeout, v.Proxy)
+
+	var models []Model
+	pageToken := ""
+	for {
+		query := url.Values{"pageSize": {"100"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+			fmt.Sprintf("%s/v1beta1/publishers/%s/models?%s", v.baseURL(), v.publisher, query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("x-goog-user-project", v.projectID(account))
+
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return nil, fmt.Errorf("status code: %d", res.StatusCode)
+		}
+		var page vertexPublisherModels
+		err = json.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range page.PublisherModels {
+			models = append(models, Model{
+				ID:      model.Name[strings.LastIndex(model.Name, "/")+1:],
+				Object:  "model",
+				OwnedBy: v.publisher,
+			})
+		}
+		if page.NextPageToken == "" {
+			return models, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
@@ -0,0 +1,134 @@
+package providers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/tidwall/gjson"
+)
+
+// newVertexTokenServer 校验 JWT 签名并签发访问令牌
+func newVertexTokenServer(t *testing.T, key *rsa.PrivateKey, exchanges *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges.Add(1)
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || len(parts) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if gjson.GetBytes(claims, "iss").String() != "sa@proj.iam.gserviceaccount.com" || gjson.GetBytes(claims, "aud").String() != "http://"+r.Host+r.URL.Path {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"access_token":"ya29.token","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+}
+
+func TestVertexBuildReq(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() unexpected error: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	account, _ := json.Marshal(googleServiceAccount{
+		ProjectID:    "proj",
+		PrivateKeyID: "kid",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "sa@proj.iam.gserviceaccount.com",
+	})
+
+	var exchanges atomic.Int32
+	tokenServer := newVertexTokenServer(t, key, &exchanges)
+	defer tokenServer.Close()
+
+	config, _ := json.Marshal(map[string]any{
+		"region":          "us-east5",
+		"token_url":       tokenServer.URL + "/token",
+		"service_account": string(account),
+	})
+
+	tests := []struct {
+		name       string
+		typ        string
+		stream     bool
+		body       string
+		wantURL    string
+		wantAnthro bool
+	}{
+		{
+			name:    "gemini stream",
+			typ:     consts.ProviderVertex,
+			stream:  true,
+			body:    `{"contents":[]}`,
+			wantURL: "https://us-east5-aiplatform.googleapis.com/v1/projects/proj/locations/us-east5/publishers/google/models/gemini-2.5-pro:streamGenerateContent?alt=sse",
+		},
+		{
+			name:       "anthropic stream",
+			typ:        consts.ProviderVertexAnthropic,
+			body:       `{"model":"claude","stream":true,"messages":[]}`,
+			wantURL:    "https://us-east5-aiplatform.googleapis.com/v1/projects/proj/locations/us-east5/publishers/anthropic/models/claude-sonnet-4@20250514:streamRawPredict",
+			wantAnthro: true,
+		},
+		{
+			name:       "anthropic",
+			typ:        consts.ProviderVertexAnthropic,
+			body:       `{"model":"claude","messages":[]}`,
+			wantURL:    "https://us-east5-aiplatform.googleapis.com/v1/projects/proj/locations/us-east5/publishers/anthropic/models/claude-sonnet-4@20250514:rawPredict",
+			wantAnthro: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New(tt.typ, string(config), "")
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			model := "gemini-2.5-pro"
+			if tt.wantAnthro {
+				model = "claude-sonnet-4@20250514"
+			}
+			ctx := context.WithValue(context.Background(), consts.ContextKeyGeminiStream, tt.stream)
+			req, err := provider.BuildReq(ctx, http.Header{}, model, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("BuildReq() unexpected error: %v", err)
+			}
+			if got := req.URL.String(); got != tt.wantURL {
+				t.Errorf("url = %q, want %q", got, tt.wantURL)
+			}
+			if got := req.Header.Get("Authorization"); got != "Bearer ya29.token" {
+				t.Errorf("authorization = %q", got)
+			}
+			if tt.wantAnthro {
+				body, _ := io.ReadAll(req.Body)
+				if gjson.GetBytes(body, "model").Exists() || gjson.GetBytes(body, "anthropic_version").String() != vertexAnthropicVersion {
+					t.Errorf("unexpected body: %s", body)
+				}
+			}
+		})
+	}
+	// 令牌在有效期内复用
+	if got := exchanges.Load(); got != 1 {
+		t.Errorf("token exchanges = %d, want 1", got)
+	}
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/models"
+	"github.com/atopos31/llmio/pkg/env"
+	"github.com/atopos31/llmio/pkg/token"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"gorm.io/gorm"
+)
+
+type batchEndpoint struct {
+	style     consts.Style
+	beforer   Beforer
+	processer Processer
+}
+
+// batchEndpoints Batch 支持的接口
+var batchEndpoints = map[string]batchEndpoint{
+	"/v1/chat/completions": {consts.StyleOpenAI, BeforerOpenAI, ProcesserOpenAI},
+	"/v1/responses":        {consts.StyleOpenAIRes, BeforerOpenAIRes, ProcesserOpenAiRes},
+	"/v1/completions":      {consts.StyleOpenAI, BeforerOpenAICompletions, ProcesserOpenAI},
+	"/v1/embeddings":       {consts.StyleOpenAI, BeforerOpenAIEmbeddings, ProcesserOpenAIEmbeddings},
+}
+
+// runningBatches 正在执行的批处理任务 batchID -> context.CancelFunc
+var runningBatches sync.Map
+
+func batchConcurrency() int {
+	n, err := strconv.Atoi(env.GetWithDefault("LLMIO_BATCH_CONCURRENCY", "4"))
+	if err != nil || n <= 0 {
+		return 4
+	}
+	return n
+}
+
+func NewFileID() (string, error) {
+	id, err := token.GenerateRandomChars(24)
+	if err != nil {
+		return "", err
+	}
+	return "file-" + id, nil
+}
+
+// CreateBatch 校验输入文件并启动后台任务，校验失败的任务状态为 failed
+func CreateBatch(ctx context.Context, inputFileID, endpoint, completionWindow string, metadata map[string]string) (*models.Batch, error) {
+	if _, ok := batchEndpoints[endpoint]; !ok {
+		return nil, fmt.Errorf("unsupported batch endpoint: %s", endpoint)
+	}
+	if completionWindow != "24h" {
+		return nil, fmt.Errorf("unsupported completion window: %s", completionWindow)
+	}
+	authKeyID, _ := ctx.Value(consts.ContextKeyAuthKeyID).(uint)
+	allowAll, _ := ctx.Value(consts.ContextKeyAllowAllModel).(bool)
+	allowModels, _ := ctx.Value(consts.ContextKeyAllowModels).([]string)
+
+	file, err := gorm.G[models.File](models.DB).Where("file_id = ?", inputFileID).Where("auth_key_id = ?", authKeyID).First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("input file not found: %s", inputFileID)
+		}
+		return nil, err
+	}
+
+	id, err := token.GenerateRandomChars(24)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	batch := models.Batch{
+		BatchID:          "batch_" + id,
+		AuthKeyID:        authKeyID,
+		AllowAll:         allowAll,
+		AllowModels:      allowModels,
+		Endpoint:         endpoint,
+		InputFileID:      inputFileID,
+		CompletionWindow: completionWindow,
+		Status:           consts.BatchStatusInProgress,
+		Metadata:         metadata,
+		ExpiresAt:        now.Add(24 * time.Hour),
+		InProgressAt:     &now,
+	}
+	lines, batchErrors := parseBatchInput(file.Content, endpoint)
+	batch.Total = len(lines)
+	if len(batchErrors) > 0 {
+		batch.Status = consts.BatchStatusFailed
+		batch.Errors = batchErrors
+		batch.InProgressAt = nil
+		batch.FailedAt = &now
+	}
+	if err := gorm.G[models.Batch](models.DB).Create(ctx, &batch); err != nil {
+		return nil, err
+	}
+	if batch.Status == consts.BatchStatusInProgress {
+		startBatch(batch)
+	}
+	return &batch, nil
+}
+
+// parseBatchInput 解析 JSONL 输入文件，每行需包含唯一的 custom_id 且 url 与 endpoint 一致
+func parseBatchInput(content []byte, endpoint string) ([]gjson.Result, []models.BatchError) {
+	var lines []gjson.Result
+	var batchErrors []models.BatchError
+	customIDs := make(map[string]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, InitScannerBufferSize), MaxScannerBufferSize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		lineErr := func(code, message string) {
+			batchErrors = append(batchErrors, models.BatchError{Code: code, Message: message, Line: &lineNo})
+		}
+		if !gjson.ValidBytes(line) {
+			lineErr("invalid_json_line", "line is not valid JSON")
+			continue
+		}
+		request := gjson.ParseBytes(slices.Clone(line))
+		customID := request.Get("custom_id").String()
+		switch {
+		case customID == "":
+			lineErr("missing_required_parameter", "custom_id is required")
+		case request.Get("method").String() != http.MethodPost:
+			lineErr("invalid_method", "method must be POST")
+		case request.Get("url").String() != endpoint:
+			lineErr("mismatched_endpoint", fmt.Sprintf("url must be %s", endpoint))
+		case !request.Get("body").IsObject():
+			lineErr("missing_required_parameter", "body must be an object")
+		default:
+			if _, ok := customIDs[customID]; ok {
+				lineErr("duplicate_custom_id", "custom_id must be unique: "+customID)
+				continue
+			}
+			customIDs[customID] = struct{}{}
+			lines = append(lines, request)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		batchErrors = append(batchErrors, models.BatchError{Code: "invalid_file", Message: err.Error()})
+	}
+	if len(lines) == 0 && len(batchErrors) == 0 {
+		batchErrors = append(batchErrors, models.BatchError{Code: "empty_file", Message: "input file has no requests"})
+	}
+	return lines, batchErrors
+}
+
+// CancelBatch 取消任务，已完成的请求仍会写入结果文件
+func CancelBatch(ctx context.Context, batch models.Batch) (*models.Batch, error) {
+	if batch.Status != consts.BatchStatusInProgress && batch.Status != consts.BatchStatusValidating {
+		return nil, fmt.Errorf("cannot cancel batch with status %s", batch.Status)
+	}
+	now := time.Now()
+	batch.Status = consts.BatchStatusCancelling
+	batch.CancellingAt = &now
+	if _, err := gorm.G[models.Batch](models.DB).Where("id = ?", batch.ID).Updates(ctx, models.Batch{
+		Status:       batch.Status,
+		CancellingAt: batch.CancellingAt,
+	}); err != nil {
+		return nil, err
+	}
+	if cancel, ok := runningBatches.Load(batch.BatchID); ok {
+		cancel.(context.CancelFunc)()
+	} else {
+		go finalizeBatch(context.Background(), batch, context.Canceled)
+	}
+	return &batch, nil
+}
+
+// ResumeBatches 服务启动时恢复未完成的任务
+func ResumeBatches(ctx context.Context) error {
+	batches, err := gorm.G[models.Batch](models.DB).
+		Where("status IN ?", []string{consts.BatchStatusInProgress, consts.BatchStatusFinalizing, consts.BatchStatusCancelling}).
+		Find(ctx)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		if batch.Status == consts.BatchStatusCancelling {
+			go finalizeBatch(context.Background(), batch, context.Canceled)
+			continue
+		}
+		startBatch(batch)
+	}
+	return nil
+}
+
+func startBatch(batch models.Batch) {
+	ctx, cancel := context.WithDeadline(context.Background(), batch.ExpiresAt)
+	runningBatches.Store(batch.BatchID, cancel)
+	go func() {
+		defer runningBatches.Delete(batch.BatchID)
+		defer cancel()
+		runBatch(ctx, batch)
+	}()
+}
+
+func runBatch(ctx context.Context, batch models.Batch) {
+	file, err := gorm.G[models.File](models.DB).Where("file_id = ?", batch.InputFileID).First(ctx)
+	if err != nil {
+		slog.Error("load batch input file error", "batch", batch.BatchID, "error", err)
+		finalizeBatch(context.Background(), batch, err)
+		return
+	}
+	lines, _ := parseBatchInput(file.Content, batch.Endpoint)
+
+	results, err := gorm.G[models.BatchResult](models.DB).Select("line").Where("batch_id = ?", batch.BatchID).Find(ctx)
+	if err != nil {
+		slog.Error("load batch results error", "batch", batch.BatchID, "error", err)
+		finalizeBatch(context.Background(), batch, err)
+		return
+	}
+	done := make(map[int]struct{}, len(results))
+	for _, result := range results {
+		done[result.Line] = struct{}{}
+	}
+
+	// 按 AuthKey 记录日志
+	ctx = context.WithValue(ctx, consts.ContextKeyAuthKeyID, batch.AuthKeyID)
+
+	sem := make(chan struct{}, batchConcurrency())
+	var wg sync.WaitGroup
+dispatch:
+	for i, line := range lines {
+		if _, ok := done[i]; ok {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			result := runBatchLine(ctx, batch, i, line)
+			// 任务被取消或过期时中断的请求不记录结果
+			if ctx.Err() != nil {
+				return
+			}
+			if err := saveBatchResult(context.Background(), result); err != nil {
+				slog.Error("save batch result error", "batch", batch.BatchID, "error", err)
+			}
+		})
+	}
+	wg.Wait()
+	finalizeBatch(context.Background(), batch, ctx.Err())
+}
+
+func saveBatchResult(ctx context.Context, result models.BatchResult) error {
+	return models.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&result).Error; err != nil {
+			return err
+		}
+		column := lo.Ternary(result.Failed, "failed", "completed")
+		return tx.Model(&models.Batch{}).Where("batch_id = ?", result.BatchID).UpdateColumn(column, gorm.Expr(column+" + 1")).Error
+	})
+}
+
+func runBatchLine(ctx context.Context, batch models.Batch, line int, request gjson.Result) models.BatchResult {
+	result := models.BatchResult{BatchID: batch.BatchID, Line: line}
+	requestID, err := token.GenerateRandomChars(24)
+	if err != nil {
+		requestID = strconv.Itoa(line)
+	}
+	output := map[string]any{
+		"id":        "batch_req_" + requestID,
+		"custom_id": request.Get("custom_id").String(),
+		"response":  nil,
+		"error":     nil,
+	}
+	body, traceID, err := runBatchRequest(ctx, batch, request.Get("body").Raw)
+	if err != nil {
+		result.Failed = true
+		output["error"] = map[string]string{"code": "request_failed", "message": err.Error()}
+	} else {
+		output["response"] = map[string]any{
+			"status_code": http.StatusOK,
+			"request_id":  traceID,
+			"body":        json.RawMessage(body),
+		}
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		result.Failed = true
+		data = fmt.Appendf(nil, `{"custom_id":%q,"response":null,"error":{"code":"server_error","message":%q}}`, request.Get("custom_id").String(), err.Error())
+	}
+	result.Output = string(data)
+	return result
+}
+
+// runBatchRequest 与 chat 接口一致：校验模型权限、负载均衡调用提供商并记录日志
+func runBatchRequest(ctx context.Context, batch models.Batch, rawBody string) ([]byte, string, error) {
+	endpoint := batchEndpoints[batch.Endpoint]
+	// 批处理不支持流式
+	data, err := sjson.DeleteBytes([]byte(rawBody), "stream")
+	if err != nil {
+		return nil, "", err
+	}
+	before, err := endpoint.beforer(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if !batch.AllowAll && !slices.Contains(batch.AllowModels, before.Model) {
+		return nil, "", errors.New("auth key has no permission to use this model")
+	}
+	if err := ResolveConversation(ctx, endpoint.style, before); err != nil {
+		return nil, "", err
+	}
+	providersWithMeta, err := ProvidersWithMetaBymodelsName(ctx, endpoint.style, *before)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start := time.Now()
+	res, log, err := BalanceChat(ctx, start, endpoint.style, *before, *providersWithMeta, models.ReqMeta{
+		Header:    http.Header{},
+		UserAgent: "llmio-batch",
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	logId, err := SaveChatLog(ctx, *log)
+	if err != nil {
+		return nil, "", err
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	RecordLog(context.Background(), start, io.NopCloser(bytes.NewReader(body)), endpoint.processer, logId, *before, providersWithMeta.IOLog)
+	return body, log.TraceID, nil
+}
+
+// finalizeBatch 生成结果文件与错误文件，cause 决定最终状态
+func finalizeBatch(ctx context.Context, batch models.Batch, cause error) {
+	now := time.Now()
+	if _, err := gorm.G[models.Batch](models.DB).Where("id = ?", batch.ID).Updates(ctx, models.Batch{
+		Status:       lo.Ternary(batch.Status == consts.BatchStatusCancelling, consts.BatchStatusCancelling, consts.BatchStatusFinalizing),
+		FinalizingAt: &now,
+	}); err != nil {
+		slog.Error("update batch status error", "batch", batch.BatchID, "error", err)
+	}
+
+	update := models.Batch{}
+	switch {
+	case cause == nil:
+		update.Status, update.CompletedAt = consts.BatchStatusCompleted, &now
+	case errors.Is(cause, context.DeadlineExceeded):
+		update.Status, update.ExpiredAt = consts.BatchStatusExpired, &now
+	case errors.Is(cause, context.Canceled):
+		update.Status, update.CancelledAt = consts.BatchStatusCancelled, &now
+	default:
+		update.Status, update.FailedAt = consts.BatchStatusFailed, &now
+		update.Errors = []models.BatchError{{Code: "server_error", Message: cause.Error()}}
+	}
+
+	err := func() error {
+		results, err := gorm.G[models.BatchResult](models.DB).Where("batch_id = ?", batch.BatchID).Order("line ASC").Find(ctx)
+		if err != nil {
+			return err
+		}
+		var output, errorOutput bytes.Buffer
+		for _, result := range results {
+			buf := lo.Ternary(result.Failed, &errorOutput, &output)
+			buf.WriteString(result.Output)
+			buf.WriteByte('\n')
+		}
+		if update.OutputFileID, err = createBatchFile(ctx, batch, "output", output.Bytes()); err != nil {
+			return err
+		}
+		if update.ErrorFileID, err = createBatchFile(ctx, batch, "error", errorOutput.Bytes()); err != nil {
+			return err
+		}
+		_, err = gorm.G[models.Batch](models.DB).Where("id = ?", batch.ID).Updates(ctx, update)
+		return err
+	}()
+	if err != nil {
+		slog.Error("finalize batch error", "batch", batch.BatchID, "error", err)
+	}
+}
+
+// createBatchFile 保存结果文件，内容为空时不生成
+func createBatchFile(ctx context.Context, batch models.Batch, kind string, content []byte) (string, error) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	fileID, err := NewFileID()
+	if err != nil {
+		return "", err
+	}
+	return fileID, gorm.G[models.File](models.DB).Create(ctx, &models.File{
+		FileID:    fileID,
+		AuthKeyID: batch.AuthKeyID,
+		Filename:  fmt.Sprintf("%s_%s.jsonl", batch.BatchID, kind),
+		Purpose:   "batch_output",
+		Bytes:     len(content),
+		Content:   content,
+	})
+}
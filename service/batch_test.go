@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/models"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+	"gorm.io/gorm"
+)
+
+func TestParseBatchInput(t *testing.T) {
+	content := strings.Join([]string{
+		`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"m"}}`,
+		``,
+		`not json`,
+		`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"m"}}`,
+		`{"custom_id":"b","method":"POST","url":"/v1/embeddings","body":{"model":"m"}}`,
+		`{"method":"POST","url":"/v1/chat/completions","body":{"model":"m"}}`,
+	}, "\n")
+
+	lines, batchErrors := parseBatchInput([]byte(content), "/v1/chat/completions")
+	if len(lines) != 1 {
+		t.Fatalf("got %d valid lines, want 1", len(lines))
+	}
+	wantCodes := map[int]string{3: "invalid_json_line", 4: "duplicate_custom_id", 5: "mismatched_endpoint", 6: "missing_required_parameter"}
+	if len(batchErrors) != len(wantCodes) {
+		t.Fatalf("got %d errors, want %d: %+v", len(batchErrors), len(wantCodes), batchErrors)
+	}
+	for _, batchErr := range batchErrors {
+		if want := wantCodes[*batchErr.Line]; batchErr.Code != want {
+			t.Errorf("line %d code = %q, want %q", *batchErr.Line, batchErr.Code, want)
+		}
+	}
+}
+
+func TestCreateBatchRunsRequests(t *testing.T) {
+	setupTestDB(t, &models.Provider{}, &models.Model{}, &models.ModelWithProvider{}, &models.ChatLog{}, &models.ChatIO{}, &models.File{}, &models.Batch{}, &models.BatchResult{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		prompt := gjson.GetBytes(body, "messages.0.content").String()
+		if prompt == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"bad request"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"c1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"echo %s"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3}}`, prompt)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	provider := models.Provider{Name: "stub", Type: consts.StyleOpenAI, Config: fmt.Sprintf(`{"base_url":%q,"api_key":"k"}`, server.URL)}
+	model := models.Model{Name: "gpt-batch", MaxRetry: 1, TimeOut: 30, Strategy: consts.BalancerDefault}
+	lo.Must0(models.DB.Create(&provider).Error)
+	lo.Must0(models.DB.Create(&model).Error)
+	lo.Must0(models.DB.Create(&models.ModelWithProvider{ModelID: model.ID, ProviderID: provider.ID, ProviderModel: "gpt-4.1", Status: lo.ToPtr(true), Weight: 1}).Error)
+
+	content := strings.Join([]string{
+		`{"custom_id":"ok-1","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-batch","messages":[{"role":"user","content":"hi"}]}}`,
+		`{"custom_id":"bad","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-batch","messages":[{"role":"user","content":"fail"}]}}`,
+		`{"custom_id":"ok-2","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-batch","stream":true,"messages":[{"role":"user","content":"there"}]}}`,
+	}, "\n")
+	lo.Must0(models.DB.Create(&models.File{FileID: "file-in", Purpose: "batch", Content: []byte(content), Bytes: len(content)}).Error)
+
+	batchCtx := context.WithValue(ctx, consts.ContextKeyAllowAllModel, true)
+	batch, err := CreateBatch(batchCtx, "file-in", "/v1/chat/completions", "24h", nil)
+	if err != nil {
+		t.Fatalf("CreateBatch() unexpected error: %v", err)
+	}
+	if batch.Status != consts.BatchStatusInProgress || batch.Total != 3 {
+		t.Fatalf("unexpected batch: status=%s total=%d", batch.Status, batch.Total)
+	}
+
+	var finished models.Batch
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		finished = lo.Must(gorm.G[models.Batch](models.DB).Where("batch_id = ?", batch.BatchID).First(ctx))
+		if finished.Status == consts.BatchStatusCompleted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("batch not completed, status=%s", finished.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if finished.Completed != 2 || finished.Failed != 1 {
+		t.Fatalf("request counts = %d/%d, want 2/1", finished.Completed, finished.Failed)
+	}
+	output := lo.Must(gorm.G[models.File](models.DB).Where("file_id = ?", finished.OutputFileID).First(ctx))
+	outputLines := strings.Split(strings.TrimSpace(string(output.Content)), "\n")
+	if len(outputLines) != 2 {
+		t.Fatalf("got %d output lines, want 2", len(outputLines))
+	}
+	if got := gjson.Get(outputLines[1], "response.body.choices.0.message.content").String(); got != "echo there" {
+		t.Errorf("second output content = %q", got)
+	}
+	if got := gjson.Get(outputLines[0], "custom_id").String(); got != "ok-1" {
+		t.Errorf("output order custom_id = %q", got)
+	}
+	errorFile := lo.Must(gorm.G[models.File](models.DB).Where("file_id = ?", finished.ErrorFileID).First(ctx))
+	if got := gjson.GetBytes(errorFile.Content, "custom_id").String(); got != "bad" {
+		t.Errorf("error file custom_id = %q", got)
+	}
+}
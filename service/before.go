@@ -1,9 +1,16 @@
 package service
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/atopos31/llmio/consts"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -11,10 +18,15 @@ import (
 type Before struct {
 	Model            string
 	Stream           bool
+	endpoint         consts.Endpoint // 请求的接口类型
+	contentType      string          // 非 JSON 请求体的 Content-Type，如 multipart/form-data
+	units            float64         // 请求侧即可确定的非 token 用量，如 TTS 字符数
+	unit             string
 	toolCall         bool
 	structuredOutput bool
 	image            bool
 	raw              []byte
+	conversation     *conversation // Responses 会话状态，由 ResolveConversation 设置
 }
 
 type Beforer func(data []byte) (*Before, error)
@@ -120,6 +132,7 @@ func NewBeforerGemini(model string, stream bool) Beforer {
 			toolCall:         toolCall,
 			structuredOutput: structuredOutput,
 			image:            image,
+			endpoint:         consts.EndpointChat,
 			raw:              data,
 		}, nil
 	}
@@ -172,6 +185,7 @@ func BeforerOpenAI(data []byte) (*Before, error) {
 		toolCall:         toolCall,
 		structuredOutput: structuredOutput,
 		image:            image,
+		endpoint:         consts.EndpointChat,
 		raw:              data,
 	}, nil
 }
@@ -213,6 +227,7 @@ func BeforerOpenAIRes(data []byte) (*Before, error) {
 		toolCall:         toolCall,
 		structuredOutput: structuredOutput,
 		image:            image,
+		endpoint:         consts.EndpointChat,
 		raw:              data,
 	}, nil
 }
@@ -250,6 +265,213 @@ func BeforerAnthropic(data []byte) (*Before, error) {
 		toolCall:         toolCall,
 		structuredOutput: toolCall,
 		image:            image,
+		endpoint:         consts.EndpointChat,
 		raw:              data,
 	}, nil
 }
+
+// BeforerOllama Ollama /api/chat 未指定 stream 时默认流式输出
+func BeforerOllama(data []byte) (*Before, error) {
+	model := gjson.GetBytes(data, "model").String()
+	if model == "" {
+		return nil, errors.New("model is empty")
+	}
+	stream := true
+	if v := gjson.GetBytes(data, "stream"); v.Exists() {
+		stream = v.Bool()
+	}
+	var toolCall bool
+	tools := gjson.GetBytes(data, "tools")
+	if tools.Exists() && len(tools.Array()) != 0 {
+		toolCall = true
+	}
+	var structuredOutput bool
+	if gjson.GetBytes(data, "format").Exists() {
+		structuredOutput = true
+	}
+	var image bool
+	gjson.GetBytes(data, "messages").ForEach(func(_, value gjson.Result) bool {
+		if len(value.Get("images").Array()) != 0 {
+			image = true
+			return false
+		}
+		return true
+	})
+	return &Before{
+		Model:            model,
+		Stream:           stream,
+		toolCall:         toolCall,
+		structuredOutput: structuredOutput,
+		image:            image,
+		endpoint:         consts.EndpointChat,
+		raw:              data,
+	}, nil
+}
+
+// BeforerOpenAICompletions 解析旧版 /completions 请求，prompt + suffix 即 FIM 补全
+func BeforerOpenAICompletions(data []byte) (*Before, error) {
+	model := gjson.GetBytes(data, "model").String()
+	if model == "" {
+		return nil, errors.New("model is empty")
+	}
+	stream := gjson.GetBytes(data, "stream").Bool()
+	if stream {
+		// 与 chat 一致，开启 include_usage 以便从流式分片中记录用量
+		newData, err := sjson.SetBytes(data, "stream_options", struct {
+			IncludeUsage bool `json:"include_usage"`
+		}{IncludeUsage: true})
+		if err != nil {
+			return nil, err
+		}
+		data = newData
+	}
+	return &Before{
+		Model:    model,
+		Stream:   stream,
+		endpoint: consts.EndpointCompletions,
+		raw:      data,
+	}, nil
+}
+
+func BeforerOpenAIEmbeddings(data []byte) (*Before, error) {
+	model := gjson.GetBytes(data, "model").String()
+	if model == "" {
+		return nil, errors.New("model is empty")
+	}
+	return &Before{
+		Model:    model,
+		endpoint: consts.EndpointEmbeddings,
+		raw:      data,
+	}, nil
+}
+
+// BeforerRerank 解析 Cohere / Jina 风格的重排序请求
+func BeforerRerank(data []byte) (*Before, error) {
+	model := gjson.GetBytes(data, "model").String()
+	if model == "" {
+		return nil, errors.New("model is empty")
+	}
+	return &Before{
+		Model:    model,
+		endpoint: consts.EndpointRerank,
+		raw:      data,
+	}, nil
+}
+
+// NewBeforerGeminiEmbeddings 解析 Gemini embedContent / batchEmbedContents 请求，模型来自 URL 路径
+func NewBeforerGeminiEmbeddings(model string, endpoint consts.Endpoint) Beforer {
+	return func(data []byte) (*Before, error) {
+		if model == "" {
+			return nil, errors.New("model is empty")
+		}
+		return &Before{
+			Model:    model,
+			endpoint: endpoint,
+			raw:      data,
+		}, nil
+	}
+}
+
+// NewBeforerImages 解析 JSON 格式的图片生成请求
+func NewBeforerImages(endpoint consts.Endpoint) Beforer {
+	return func(data []byte) (*Before, error) {
+		model := gjson.GetBytes(data, "model").String()
+		if model == "" {
+			return nil, errors.New("model is empty")
+		}
+		return &Before{
+			Model:    model,
+			Stream:   gjson.GetBytes(data, "stream").Bool(),
+			endpoint: endpoint,
+			raw:      data,
+		}, nil
+	}
+}
+
+// BeforerOpenAISpeech 解析 TTS 请求，按输入字符数计量
+func BeforerOpenAISpeech(data []byte) (*Before, error) {
+	model := gjson.GetBytes(data, "model").String()
+	if model == "" {
+		return nil, errors.New("model is empty")
+	}
+	return &Before{
+		Model:    model,
+		Stream:   gjson.GetBytes(data, "stream_format").String() == "sse",
+		endpoint: consts.EndpointAudioSpeech,
+		units:    float64(utf8.RuneCountInString(gjson.GetBytes(data, "input").String())),
+		unit:     consts.UnitCharacters,
+		raw:      data,
+	}, nil
+}
+
+// Streaming 响应是否需要边读边转发，二进制音频同样需要
+func (b Before) Streaming() bool {
+	return b.Stream || b.endpoint == consts.EndpointAudioSpeech
+}
+
+// NewBeforerMultipart 解析 multipart/form-data 请求，从表单字段中提取 model 与 stream
+func NewBeforerMultipart(contentType string, endpoint consts.Endpoint) Beforer {
+	return func(data []byte) (*Before, error) {
+		fields, err := multipartFields(contentType, data)
+		if err != nil {
+			return nil, err
+		}
+		model := fields["model"]
+		if model == "" {
+			return nil, errors.New("model is empty")
+		}
+		return &Before{
+			Model:       model,
+			Stream:      fields["stream"] == "true",
+			endpoint:    endpoint,
+			contentType: contentType,
+			raw:         data,
+		}, nil
+	}
+}
+
+// multipartFields 读取 multipart 表单中的非文件字段
+func multipartFields(contentType string, data []byte) (map[string]string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, errors.New("invalid multipart content type: " + contentType)
+	}
+	fields := make(map[string]string)
+	reader := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return fields, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() != "" {
+			continue
+		}
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		fields[part.FormName()] = string(value)
+	}
+}
+
+// input 返回用于 IO 记录的请求内容，multipart 请求只记录非文件字段
+func (b Before) input() string {
+	if b.contentType == "" {
+		return string(b.raw)
+	}
+	fields, err := multipartFields(b.contentType, b.raw)
+	if err != nil {
+		return err.Error()
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
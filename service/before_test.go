@@ -0,0 +1,64 @@
+package service
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/tidwall/gjson"
+)
+
+func TestNewBeforerMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	file, _ := writer.CreateFormFile("file", "speech.mp3")
+	file.Write([]byte("ID3"))
+	writer.WriteField("model", "whisper-1")
+	writer.WriteField("stream", "true")
+	writer.Close()
+
+	before, err := NewBeforerMultipart(writer.FormDataContentType(), consts.EndpointAudioTranscriptions)(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if before.Model != "whisper-1" || !before.Stream {
+		t.Fatalf("unexpected before: model=%q stream=%v", before.Model, before.Stream)
+	}
+	if got := before.input(); got != `{"model":"whisper-1","stream":"true"}` {
+		t.Fatalf("input = %s", got)
+	}
+
+	if _, err := NewBeforerMultipart("application/json", consts.EndpointAudioTranscriptions)([]byte(`{"model":"whisper-1"}`)); err == nil {
+		t.Fatal("expected error for non multipart body")
+	}
+}
+
+func TestBeforerOpenAISpeech(t *testing.T) {
+	before, err := BeforerOpenAISpeech([]byte(`{"model":"tts-1","input":"你好, world","voice":"alloy"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if before.units != 9 || before.unit != consts.UnitCharacters {
+		t.Fatalf("units = %v %s, want 9 characters", before.units, before.unit)
+	}
+	if !before.Streaming() || before.Stream {
+		t.Fatal("speech should be forwarded as a binary stream")
+	}
+}
+
+func TestBeforerOpenAICompletions(t *testing.T) {
+	before, err := BeforerOpenAICompletions([]byte(`{"model":"deepseek-coder","prompt":"def add(a, b):","suffix":"    return c","stream":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if before.Model != "deepseek-coder" || !before.Stream || before.endpoint != consts.EndpointCompletions {
+		t.Fatalf("unexpected before: %+v", before)
+	}
+	if !gjson.GetBytes(before.raw, "stream_options.include_usage").Bool() {
+		t.Fatal("expected include_usage to be enabled for streamed completions")
+	}
+	if gjson.GetBytes(before.raw, "suffix").String() != "    return c" {
+		t.Fatal("suffix should be kept")
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/atopos31/llmio/balancers"
+	"github.com/atopos31/llmio/models"
+	"gorm.io/gorm"
+)
+
+// InitBreakers 恢复持久化的熔断状态，并在之后的状态变更时写回数据库
+func InitBreakers(ctx context.Context) error {
+	states, err := gorm.G[models.BreakerState](models.DB).Find(ctx)
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		balancers.RestoreNode(state.ModelWithProviderID, balancers.State(state.State), state.Expiry, state.Forced)
+	}
+
+	queue := &breakerQueue{states: make(map[uint]balancers.Transition), notify: make(chan struct{}, 1)}
+	balancers.OnTransition = queue.push
+	go queue.run(context.Background())
+	return nil
+}
+
+// maxPendingBreakerEvents 等待写入的变更记录上限
+const maxPendingBreakerEvents = 1024
+
+// breakerQueue 在请求路径外持久化熔断状态变更。
+// 状态按关联合并，只保留最新一次，不会因积压丢失；变更记录积压超过上限时丢弃
+type breakerQueue struct {
+	mu     sync.Mutex
+	states map[uint]balancers.Transition
+	events []balancers.Transition
+	notify chan struct{}
+}
+
+func (q *breakerQueue) push(t balancers.Transition) {
+	q.mu.Lock()
+	q.states[t.Key] = t
+	dropped := len(q.events) >= maxPendingBreakerEvents
+	if !dropped {
+		q.events = append(q.events, t)
+	}
+	q.mu.Unlock()
+	if dropped {
+		slog.Warn("breaker event dropped", "model_provider", t.Key, "from", t.From, "to", t.To, "reason", t.Reason)
+	}
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *breakerQueue) run(ctx context.Context) {
+	for range q.notify {
+		q.mu.Lock()
+		states, events := q.states, q.events
+		q.states, q.events = make(map[uint]balancers.Transition), nil
+		q.mu.Unlock()
+
+		for _, t := range states {
+			if err := saveBreakerState(ctx, t); err != nil {
+				slog.Error("save breaker state error", "error", err)
+			}
+		}
+		for _, t := range events {
+			if err := saveBreakerEvent(ctx, t); err != nil {
+				slog.Error("save breaker event error", "error", err)
+			}
+		}
+	}
+}
+
+func saveBreakerState(ctx context.Context, t balancers.Transition) error {
+	slog.Info("breaker transition", "model_provider", t.Key, "from", t.From, "to", t.To, "reason", t.Reason)
+	state := models.BreakerState{
+		ModelWithProviderID: t.Key,
+		State:               int(t.To),
+		Expiry:              t.Expiry,
+		Forced:              t.Forced,
+	}
+	_, err := gorm.G[models.BreakerState](models.DB).Where("model_with_provider_id = ?", t.Key).First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return gorm.G[models.BreakerState](models.DB).Create(ctx, &state)
+	}
+	if err != nil {
+		return err
+	}
+	// 零值字段需显式更新
+	_, err = gorm.G[models.BreakerState](models.DB).Where("model_with_provider_id = ?", t.Key).
+		Select("state", "expiry", "forced").
+		Updates(ctx, state)
+	return err
+}
+
+func saveBreakerEvent(ctx context.Context, t balancers.Transition) error {
+	return gorm.G[models.BreakerEvent](models.DB).Create(ctx, &models.BreakerEvent{
+		ModelWithProviderID: t.Key,
+		From:                t.From.String(),
+		To:                  t.To.String(),
+		FailCount:           t.FailCount,
+		Reason:              t.Reason,
+		Expiry:              t.Expiry,
+	})
+}
+
+// DeleteBreakers 删除关联的熔断状态与变更记录，关联被删除时调用
+func DeleteBreakers(ctx context.Context, modelWithProviderIDs ...uint) error {
+	if len(modelWithProviderIDs) == 0 {
+		return nil
+	}
+	for _, id := range modelWithProviderIDs {
+		balancers.DeleteNode(id)
+	}
+	if _, err := gorm.G[models.BreakerState](models.DB).Where("model_with_provider_id IN ?", modelWithProviderIDs).Delete(ctx); err != nil {
+		return err
+	}
+	_, err := gorm.G[models.BreakerEvent](models.DB).Where("model_with_provider_id IN ?", modelWithProviderIDs).Delete(ctx)
+	return err
+}
+
+// breakerConfig 将模型配置的熔断参数转换为 balancers 配置
+func breakerConfig(config models.BreakerConfig) balancers.BreakerConfig {
+	return balancers.BreakerConfig{
+		MaxFailures: config.MaxFailures,
+		SleepWindow: time.Duration(config.SleepWindow) * time.Second,
+		MaxRequests: config.MaxRequests,
+		FailureRate: config.FailureRate,
+		MinRequests: config.MinRequests,
+		Window:      time.Duration(config.Window) * time.Second,
+	}
+}
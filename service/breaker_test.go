@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/atopos31/llmio/balancers"
+	"github.com/atopos31/llmio/models"
+	"gorm.io/gorm"
+)
+
+func TestSaveBreakerState(t *testing.T) {
+	setupTestDB(t, &models.BreakerState{}, &models.BreakerEvent{})
+	ctx := context.Background()
+
+	expiry := time.Now().Add(time.Minute)
+	if err := saveBreakerState(ctx, balancers.Transition{Key: 1, From: balancers.StateClosed, To: balancers.StateOpen, Expiry: expiry}); err != nil {
+		t.Fatalf("save open state: %v", err)
+	}
+	// 关闭状态为零值，需要覆盖之前的 open
+	if err := saveBreakerState(ctx, balancers.Transition{Key: 1, From: balancers.StateHalfOpen, To: balancers.StateClosed}); err != nil {
+		t.Fatalf("save closed state: %v", err)
+	}
+
+	states, err := gorm.G[models.BreakerState](models.DB).Find(ctx)
+	if err != nil {
+		t.Fatalf("query states: %v", err)
+	}
+	if len(states) != 1 || states[0].State != int(balancers.StateClosed) || !states[0].Expiry.IsZero() {
+		t.Fatalf("states = %+v, want single closed state", states)
+	}
+}
+
+func TestSaveBreakerEvent(t *testing.T) {
+	setupTestDB(t, &models.BreakerEvent{})
+	ctx := context.Background()
+
+	if err := saveBreakerEvent(ctx, balancers.Transition{Key: 3, From: balancers.StateClosed, To: balancers.StateOpen, FailCount: 5, Reason: "max failures reached"}); err != nil {
+		t.Fatalf("save event: %v", err)
+	}
+	events, err := gorm.G[models.BreakerEvent](models.DB).Find(ctx)
+	if err != nil {
+		t.Fatalf("query events: %v", err)
+	}
+	if len(events) != 1 || events[0].From != "closed" || events[0].To != "open" || events[0].FailCount != 5 {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestBreakerQueueKeepsLatestState(t *testing.T) {
+	setupTestDB(t, &models.BreakerState{}, &models.BreakerEvent{})
+	ctx := context.Background()
+
+	// 未启动写入前积压超过上限，状态仍保留最后一次
+	queue := &breakerQueue{states: make(map[uint]balancers.Transition), notify: make(chan struct{}, 1)}
+	for range maxPendingBreakerEvents {
+		queue.push(balancers.Transition{Key: 5, From: balancers.StateOpen, To: balancers.StateClosed})
+	}
+	queue.push(balancers.Transition{Key: 5, From: balancers.StateClosed, To: balancers.StateOpen, Forced: true, Reason: "forced open"})
+	done := make(chan struct{})
+	go func() {
+		queue.run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(queue.notify)
+		<-done
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		state, err := gorm.G[models.BreakerState](models.DB).Where("model_with_provider_id = ?", 5).First(ctx)
+		if err == nil && state.State == int(balancers.StateOpen) && state.Forced {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("forced open state not persisted: %+v, %v", state, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDeleteBreakers(t *testing.T) {
+	setupTestDB(t, &models.BreakerState{}, &models.BreakerEvent{})
+	ctx := context.Background()
+
+	for _, key := range []uint{7, 8} {
+		open := balancers.Transition{Key: key, From: balancers.StateClosed, To: balancers.StateOpen}
+		if err := saveBreakerState(ctx, open); err != nil {
+			t.Fatalf("save state: %v", err)
+		}
+		if err := saveBreakerEvent(ctx, open); err != nil {
+			t.Fatalf("save event: %v", err)
+		}
+	}
+	if err := DeleteBreakers(ctx, 7); err != nil {
+		t.Fatalf("DeleteBreakers() unexpected error: %v", err)
+	}
+
+	states, err := gorm.G[models.BreakerState](models.DB).Find(ctx)
+	if err != nil {
+		t.Fatalf("query states: %v", err)
+	}
+	events, err := gorm.G[models.BreakerEvent](models.DB).Find(ctx)
+	if err != nil {
+		t.Fatalf("query events: %v", err)
+	}
+	if len(states) != 1 || states[0].ModelWithProviderID != 8 || len(events) != 1 || events[0].ModelWithProviderID != 8 {
+		t.Fatalf("states = %+v, events = %+v, want only association 8", states, events)
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/atopos31/llmio/balancers"
 	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/converters"
 	"github.com/atopos31/llmio/models"
 	"github.com/atopos31/llmio/pkg/token"
 	"github.com/atopos31/llmio/providers"
@@ -21,7 +23,7 @@ import (
 )
 
 func BalanceChat(ctx context.Context, start time.Time, style string, before Before, providersWithMeta ProvidersWithMeta, reqMeta models.ReqMeta) (*http.Response, *models.ChatLog, error) {
-	slog.Info("request", "model", before.Model, "stream", before.Stream, "tool_call", before.toolCall, "structured_output", before.structuredOutput, "image", before.image)
+	slog.Info("request", "model", before.Model, "endpoint", before.endpoint, "stream", before.Stream, "tool_call", before.toolCall, "structured_output", before.structuredOutput, "image", before.image)
 
 	providerMap := providersWithMeta.ProviderMap
 
@@ -30,18 +32,40 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 
 	go RecordRetryLog(context.Background(), retryLog)
 
-	var balancer balancers.Balancer
-	switch providersWithMeta.Strategy {
-	case consts.BalancerLottery:
-		balancer = balancers.NewLottery(providersWithMeta.WeightItems)
-	case consts.BalancerRotor:
-		balancer = balancers.NewRotor(providersWithMeta.WeightItems)
-	default:
-		balancer = balancers.NewLottery(providersWithMeta.WeightItems)
+	weightItems := providersWithMeta.WeightItems
+	if providersWithMeta.Strategy == consts.BalancerSmart {
+		weightItems = smartWeightItems(ctx, before.Model, providersWithMeta)
 	}
 
+	var session string
+	if providersWithMeta.Strategy == consts.BalancerSticky {
+		session = sessionID(providersWithMeta.SessionKey, style, before.raw, reqMeta.Header)
+	}
+
+	// 按优先级分组，组内使用模型配置的策略
+	balancer := balancers.NewTiered(weightItems, providersWithMeta.TierItems, func(tier int, items map[uint]int) balancers.Balancer {
+		switch providersWithMeta.Strategy {
+		case consts.BalancerLottery, consts.BalancerSmart:
+			return balancers.NewLottery(items)
+		case consts.BalancerRotor:
+			return balancers.NewRotor(providersWithMeta.ModelID, tier, items)
+		case consts.BalancerPriority:
+			return balancers.NewPriority(items)
+		case consts.BalancerLeastConn:
+			return balancers.NewLeastConn(items)
+		case consts.BalancerCheapest:
+			return balancers.NewCheapest(items, providersWithMeta.prices())
+		case consts.BalancerSticky:
+			return balancers.NewSticky(session, items, balancers.NewLottery(maps.Clone(items)))
+		default:
+			return balancers.NewLottery(items)
+		}
+	})
+
 	if providersWithMeta.Breaker {
-		balancer = balancers.BalancerWrapperBreaker(balancer)
+		balancer = balancers.BalancerWrapperBreakerWithConfig(balancer, breakerConfig(providersWithMeta.BreakerConfig))
+	} else {
+		balancer = balancers.ExcludeForced(balancer)
 	}
 
 	responseHeaderTimeout := time.Second * time.Duration(providersWithMeta.TimeOut)
@@ -78,13 +102,6 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 
 			provider := providerMap[modelWithProvider.ProviderID]
 
-			chatModel, err := providers.New(provider.Type, provider.Config, provider.Proxy)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			client := providers.GetClientWithProxy(responseHeaderTimeout, chatModel.GetProxy())
-
 			slog.Info("using provider", "provider", provider.Name, "model", modelWithProvider.ProviderModel)
 
 			log := models.ChatLog{
@@ -94,25 +111,65 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 				ProviderName:  provider.Name,
 				Status:        consts.StatusRunning,
 				Style:         style,
+				Endpoint:      before.endpoint,
 				UserAgent:     reqMeta.UserAgent,
 				RemoteIP:      reqMeta.RemoteIP,
 				AuthKeyID:     authKeyID,
 				ChatIO:        providersWithMeta.IOLog,
+				Strategy:      providersWithMeta.Strategy,
 				Retry:         retry,
 				ProxyTime:     time.Since(start),
 			}
-			withHeader := lo.FromPtrOr(modelWithProvider.WithHeader, false)
-			headers := BuildHeaders(reqMeta.Header, withHeader, modelWithProvider.CustomerHeaders, before.Stream)
 
-			req, err := chatModel.BuildReq(ctx, headers, modelWithProvider.ProviderModel, before.raw)
+			// 多 Key 提供商从 Key 池中选择本次使用的 Key
+			config, keyPool, apiKey, err := ProviderConfig(provider)
 			if err != nil {
 				retryLog <- log.WithError(err)
 				balancer.Delete(id)
 				continue
 			}
 
-			res, err := client.Do(req)
+			withHeader := lo.FromPtrOr(modelWithProvider.WithHeader, false)
+			headers := BuildHeaders(reqMeta.Header, withHeader, modelWithProvider.CustomerHeaders, before.Stream)
+			if before.contentType != "" {
+				headers.Set("Content-Type", before.contentType)
+			}
+
+			// 提供商协议与客户端协议不一致时进行转换
+			rawBody := before.raw
+			var converter converters.Converter
+			providerStyle := providers.StyleOf(provider.Type)
+			if providerStyle != style {
+				converter, err = converters.New(style, providerStyle)
+				if c, ok := converter.(converters.Conversational); ok && err == nil && before.conversation != nil {
+					err = c.SetHistory(before.conversation.history)
+				}
+				if err == nil {
+					rawBody, err = converter.Request(before.raw, before.Stream)
+				}
+				if err != nil {
+					retryLog <- log.WithError(err)
+					balancer.Delete(id)
+					continue
+				}
+				// 转换需要读取明文响应
+				headers.Del("Accept-Encoding")
+			}
+
+			reqCtx := context.WithValue(ctx, consts.ContextKeyEndpoint, before.endpoint)
+			if providerStyle == consts.StyleGemini {
+				reqCtx = context.WithValue(reqCtx, consts.ContextKeyGeminiStream, before.Stream)
+			}
+
+			// 在途计数覆盖整个响应体，流式请求直到读取结束才释放
+			release := balancers.Acquire(id)
+
+			// 配置了多个 base URL 时，连接错误或 5xx 先切换端点，全部失败才计入提供商失败
+			chatModel, res, err := sendWithFailover(ctx, provider, config, responseHeaderTimeout, func(chatModel providers.Provider) (*http.Request, error) {
+				return chatModel.BuildReq(reqCtx, headers.Clone(), modelWithProvider.ProviderModel, rawBody)
+			})
 			if err != nil {
+				release()
 				retryLog <- log.WithError(err)
 				balancer.Delete(id)
 				continue
@@ -124,16 +181,25 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 					slog.Error("read body error", "error", err)
 				}
 				retryLog <- log.WithError(fmt.Errorf("status: %d, body: %s", res.StatusCode, string(byteBody)))
+				res.Body.Close()
+				release()
 
+				// Key 被隔离且仍有可用 Key 时不降低提供商权重，后续重试换用其他 Key
+				if keyPool != nil && keyPool.Failure(apiKey, res.StatusCode, retryAfter(res.Header)) && keyPool.Available() > 0 {
+					continue
+				}
 				if res.StatusCode == http.StatusTooManyRequests {
 					balancer.Reduce(id)
 				} else {
 					balancer.Delete(id)
 				}
-				res.Body.Close()
 				continue
 			}
 
+			if decoder, ok := chatModel.(providers.ResponseDecoder); ok {
+				decoder.DecodeResponse(res)
+			}
+
 			if provider.ErrorMatcher != "" {
 				contentType := strings.ToLower(res.Header.Get("Content-Type"))
 				if !strings.Contains(contentType, "text/event-stream") {
@@ -142,6 +208,7 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 						retryLog <- log.WithError(fmt.Errorf("read body failed: %w", err))
 						balancer.Delete(id)
 						res.Body.Close()
+						release()
 						continue
 					}
 
@@ -149,6 +216,7 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 						retryLog <- log.WithError(fmt.Errorf("response matched provider error sample %q, body: %s", sample, string(byteBody)))
 						balancer.Delete(id)
 						res.Body.Close()
+						release()
 						continue
 					}
 
@@ -157,6 +225,24 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 			}
 
 			balancer.Success(id)
+			if keyPool != nil {
+				keyPool.Success(apiKey)
+			}
+
+			if converter != nil {
+				if c, ok := converter.(converters.Conversational); ok && before.conversation != nil {
+					before.conversation.converter = c
+				}
+				res.Body = converters.WrapBody(converter, res.Body, before.Stream)
+				res.Header.Del("Content-Length")
+				res.Header.Del("Content-Encoding")
+				if before.Stream {
+					res.Header.Set("Content-Type", "text/event-stream")
+				} else {
+					res.Header.Set("Content-Type", "application/json")
+				}
+			}
+			res.Body = &releaseBody{ReadCloser: res.Body, release: release}
 
 			return res, &log, nil
 		}
@@ -165,6 +251,17 @@ func BalanceChat(ctx context.Context, start time.Time, style string, before Befo
 	return nil, nil, fmt.Errorf("All retry failed, trace ID: %s", traceID)
 }
 
+// releaseBody 响应体关闭时释放在途计数
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
 func RecordRetryLog(ctx context.Context, retryLog chan models.ChatLog) {
 	for log := range retryLog {
 		if _, err := SaveChatLog(ctx, log); err != nil {
@@ -178,7 +275,7 @@ func RecordLog(ctx context.Context, reqStart time.Time, reader io.ReadCloser, pr
 		defer reader.Close()
 		if ioLog {
 			if err := gorm.G[models.ChatIO](models.DB).Create(ctx, &models.ChatIO{
-				Input: string(before.raw),
+				Input: before.input(),
 				LogId: logId,
 			}); err != nil {
 				return err
@@ -188,6 +285,12 @@ func RecordLog(ctx context.Context, reqStart time.Time, reader io.ReadCloser, pr
 		if err != nil {
 			return err
 		}
+		if log.Unit == "" && before.unit != "" {
+			log.Units, log.Unit = before.units, before.unit
+		}
+		if err := saveConversation(ctx, before.conversation); err != nil {
+			slog.Error("save response conversation error", "error", err)
+		}
 		log.Status = consts.StatusSuccess
 		if _, err := gorm.G[models.ChatLog](models.DB).Where("id = ?", logId).Updates(ctx, *log); err != nil {
 			return err
@@ -238,14 +341,25 @@ func BuildHeaders(source http.Header, withHeader bool, customHeaders map[string]
 }
 
 type ProvidersWithMeta struct {
+	ModelID              uint
 	ModelWithProviderMap map[uint]models.ModelWithProvider
 	WeightItems          map[uint]int
+	TierItems            map[uint]int // 关联的优先级分组
 	ProviderMap          map[uint]models.Provider
 	MaxRetry             int
 	TimeOut              int
 	IOLog                bool
 	Strategy             string
+	SessionKey           string
 	Breaker              bool
+	BreakerConfig        models.BreakerConfig
+}
+
+// prices 返回各关联配置的 token 单价
+func (p ProvidersWithMeta) prices() map[uint]balancers.Price {
+	return lo.MapValues(p.ModelWithProviderMap, func(mp models.ModelWithProvider, _ uint) balancers.Price {
+		return balancers.Price{Input: mp.InputPrice, Output: mp.OutputPrice, Cached: mp.CachedPrice}
+	})
 }
 
 func ProvidersWithMetaBymodelsName(ctx context.Context, style string, before Before) (*ProvidersWithMeta, error) {
@@ -290,9 +404,27 @@ func ProvidersWithMetaBymodelsName(ctx context.Context, style string, before Bef
 
 	modelWithProviderMap := lo.KeyBy(modelWithProviders, func(mp models.ModelWithProvider) uint { return mp.ID })
 
+	// 协议转换仅支持 chat 接口，其余接口只能使用同类型提供商
+	providerTypes := []string{style}
+	if before.endpoint == consts.EndpointChat {
+		providerTypes = converters.Targets(style)
+	}
+	// previous_response_id 只能由产生它的一侧继续处理
+	switch {
+	case before.conversation.emulated():
+		providerTypes = lo.Without(providerTypes, style)
+	case before.conversation.native():
+		providerTypes = []string{style}
+	}
+	types := providers.TypesOf(providerTypes...)
+	// 自定义提供商仅支持 chat 接口
+	if before.endpoint != consts.EndpointChat {
+		types = lo.Without(types, consts.ProviderCustom)
+	}
+
 	providers, err := gorm.G[models.Provider](models.DB).
 		Where("id IN ?", lo.Map(modelWithProviders, func(mp models.ModelWithProvider, _ int) uint { return mp.ProviderID })).
-		Where("type = ?", style).
+		Where("type IN ?", types).
 		Find(ctx)
 	if err != nil {
 		return nil, err
@@ -301,21 +433,27 @@ func ProvidersWithMetaBymodelsName(ctx context.Context, style string, before Bef
 	providerMap := lo.KeyBy(providers, func(p models.Provider) uint { return p.ID })
 
 	weightItems := make(map[uint]int)
+	tierItems := make(map[uint]int)
 	for _, mp := range modelWithProviders {
 		if _, ok := providerMap[mp.ProviderID]; !ok {
 			continue
 		}
 		weightItems[mp.ID] = mp.Weight
+		tierItems[mp.ID] = mp.Tier
 	}
 
 	return &ProvidersWithMeta{
+		ModelID:              model.ID,
 		ModelWithProviderMap: modelWithProviderMap,
 		WeightItems:          weightItems,
+		TierItems:            tierItems,
 		ProviderMap:          providerMap,
 		MaxRetry:             model.MaxRetry,
 		TimeOut:              model.TimeOut,
 		IOLog:                lo.FromPtrOr(model.IOLog, false),
 		Strategy:             model.Strategy,
+		SessionKey:           model.SessionKey,
 		Breaker:              lo.FromPtrOr(model.Breaker, false),
+		BreakerConfig:        model.BreakerConfig,
 	}, nil
 }
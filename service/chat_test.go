@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/models"
+	"github.com/samber/lo"
+)
+
+func TestProvidersWithMetaExcludesCustomForNonChat(t *testing.T) {
+	db := setupTestDB(t, &models.Provider{}, &models.Model{}, &models.ModelWithProvider{})
+	ctx := context.Background()
+
+	openai := models.Provider{Name: "openai", Type: consts.StyleOpenAI, Config: `{}`}
+	custom := models.Provider{Name: "custom", Type: consts.ProviderCustom, Config: `{}`}
+	model := models.Model{Name: "m", MaxRetry: 1, TimeOut: 30}
+	lo.Must0(db.Create(&openai).Error)
+	lo.Must0(db.Create(&custom).Error)
+	lo.Must0(db.Create(&model).Error)
+	for _, provider := range []models.Provider{openai, custom} {
+		lo.Must0(db.Create(&models.ModelWithProvider{ModelID: model.ID, ProviderID: provider.ID, ProviderModel: "m", Status: lo.ToPtr(true), Weight: 1}).Error)
+	}
+
+	chat := lo.Must(BeforerOpenAI([]byte(`{"model":"m","messages":[]}`)))
+	if meta := lo.Must(ProvidersWithMetaBymodelsName(ctx, consts.StyleOpenAI, *chat)); len(meta.WeightItems) != 2 {
+		t.Errorf("chat providers = %d, want 2", len(meta.WeightItems))
+	}
+	embeddings := lo.Must(BeforerOpenAIEmbeddings([]byte(`{"model":"m","input":"hi"}`)))
+	meta := lo.Must(ProvidersWithMetaBymodelsName(ctx, consts.StyleOpenAI, *embeddings))
+	if len(meta.WeightItems) != 1 {
+		t.Fatalf("embeddings providers = %d, want 1", len(meta.WeightItems))
+	}
+	if _, ok := meta.ProviderMap[custom.ID]; ok {
+		t.Error("custom provider should not be used for embeddings")
+	}
+}
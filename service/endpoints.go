@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/atopos31/llmio/balancers"
+	"github.com/atopos31/llmio/models"
+	"github.com/atopos31/llmio/providers"
+	"github.com/tidwall/sjson"
+)
+
+// providerEndpoints 提供商配置中的多端点设置，按顺序故障切换
+type providerEndpoints struct {
+	BaseURLs []string `json:"base_urls"`
+}
+
+func providerBaseURLs(config string) []string {
+	var endpoints providerEndpoints
+	if err := json.Unmarshal([]byte(config), &endpoints); err != nil {
+		return nil
+	}
+	return endpoints.BaseURLs
+}
+
+// sendWithFailover 依次使用提供商的各 base URL 发送请求，连接错误或 5xx 时换用下一个端点，
+// 最后一个端点的结果原样返回，由调用方计入提供商失败
+func sendWithFailover(ctx context.Context, provider models.Provider, config string, timeout time.Duration, build func(providers.Provider) (*http.Request, error)) (providers.Provider, *http.Response, error) {
+	baseURLs := providerBaseURLs(config)
+	if len(baseURLs) == 0 {
+		baseURLs = []string{""}
+	}
+	ordered := balancers.EndpointOrder(provider.ID, baseURLs)
+
+	var (
+		chatModel providers.Provider
+		res       *http.Response
+		err       error
+	)
+	for i, baseURL := range ordered {
+		if i > 0 {
+			// 上一个端点失败，丢弃其响应后切换
+			if err != nil {
+				slog.Warn("endpoint failover", "provider", provider.Name, "base_url", ordered[i-1], "error", err)
+			} else {
+				slog.Warn("endpoint failover", "provider", provider.Name, "base_url", ordered[i-1], "status", res.StatusCode)
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+			}
+		}
+		endpointConfig := config
+		if baseURL != "" {
+			if endpointConfig, err = sjson.Set(config, "base_url", baseURL); err != nil {
+				return nil, nil, err
+			}
+		}
+		if chatModel, err = providers.New(provider.Type, endpointConfig, provider.Proxy); err != nil {
+			return nil, nil, err
+		}
+		var req *http.Request
+		if req, err = build(chatModel); err != nil {
+			return nil, nil, err
+		}
+		res, err = providers.GetClientWithProxy(timeout, chatModel.GetProxy()).Do(req)
+		if err == nil && res.StatusCode < http.StatusInternalServerError {
+			if i > 0 {
+				balancers.EndpointHealthy(provider.ID, baseURL)
+			}
+			return chatModel, res, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return chatModel, res, err
+}
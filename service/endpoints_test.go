@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/models"
+	"github.com/atopos31/llmio/providers"
+	"github.com/tidwall/sjson"
+)
+
+func TestSendWithFailover(t *testing.T) {
+	var badHits, goodHits int
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		badHits++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer bad.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		goodHits++
+		w.Write([]byte(`{}`))
+	}))
+	defer good.Close()
+
+	config, _ := sjson.Set(`{"api_key":"sk-test"}`, "base_urls", []string{bad.URL, good.URL})
+	provider := models.Provider{Name: "multi", Type: consts.StyleOpenAI, Config: config}
+	provider.ID = 2001
+	build := func(chatModel providers.Provider) (*http.Request, error) {
+		return chatModel.BuildReq(context.Background(), http.Header{}, "gpt-4.1", []byte(`{"model":"gpt-4.1"}`))
+	}
+
+	for range 2 {
+		_, res, err := sendWithFailover(context.Background(), provider, config, time.Second, build)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d", res.StatusCode)
+		}
+	}
+	// 第二次请求直接使用记住的可用端点
+	if badHits != 1 || goodHits != 2 {
+		t.Fatalf("bad hits = %d, good hits = %d", badHits, goodHits)
+	}
+
+	// 所有端点都失败时返回最后一个端点的响应
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	config, _ = sjson.Set(config, "base_urls", []string{closed.URL, bad.URL})
+	provider.ID = 2002
+	_, res, err := sendWithFailover(context.Background(), provider, config, time.Second, build)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadGateway || badHits != 2 {
+		t.Fatalf("status = %d, bad hits = %d", res.StatusCode, badHits)
+	}
+}
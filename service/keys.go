@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/atopos31/llmio/balancers"
+	"github.com/atopos31/llmio/models"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// providerKeys 提供商配置中的多 Key 设置
+type providerKeys struct {
+	APIKeys     []balancers.APIKey `json:"api_keys"`
+	KeyStrategy string             `json:"key_strategy"`
+}
+
+// ProviderConfig 提供商配置了 api_keys 时从 Key 池中选择一个写入 api_key 后返回配置，
+// 未配置时 pool 为 nil。端点由 sendWithFailover 选择。
+// 选择 Key 会推进轮询状态，仅用于实际转发的请求
+func ProviderConfig(provider models.Provider) (config string, pool *balancers.KeyPool, key string, err error) {
+	config = provider.Config
+	var keys providerKeys
+	if err := json.Unmarshal([]byte(config), &keys); err != nil || len(keys.APIKeys) == 0 {
+		return config, nil, "", nil
+	}
+	pool = balancers.GetKeyPool(provider.ID, keys.KeyStrategy, keys.APIKeys)
+	key, err = pool.Pick()
+	if err != nil {
+		return "", nil, "", err
+	}
+	config, err = sjson.Set(config, "api_key", key)
+	if err != nil {
+		return "", nil, "", err
+	}
+	return config, pool, key, nil
+}
+
+// AdminProviderConfig 返回管理接口使用的提供商配置，固定使用第一个 Key 与第一个端点，
+// 不影响 Key 池的轮询状态与调用统计
+func AdminProviderConfig(provider models.Provider) (string, error) {
+	config := provider.Config
+	if baseURLs := providerBaseURLs(config); len(baseURLs) > 0 && !gjson.Get(config, "base_url").Exists() {
+		var err error
+		if config, err = sjson.Set(config, "base_url", baseURLs[0]); err != nil {
+			return "", err
+		}
+	}
+	var keys providerKeys
+	if err := json.Unmarshal([]byte(config), &keys); err != nil || len(keys.APIKeys) == 0 {
+		return config, nil
+	}
+	return sjson.Set(config, "api_key", keys.APIKeys[0].Key)
+}
+
+// ProviderKeyStats 返回提供商各 Key 的调用统计与隔离状态，未配置 api_keys 时返回空列表
+func ProviderKeyStats(provider models.Provider) []balancers.KeyStat {
+	var keys providerKeys
+	if err := json.Unmarshal([]byte(provider.Config), &keys); err != nil || len(keys.APIKeys) == 0 {
+		return []balancers.KeyStat{}
+	}
+	return balancers.GetKeyPool(provider.ID, keys.KeyStrategy, keys.APIKeys).Stats()
+}
+
+// retryAfter 解析秒数形式的 Retry-After 响应头
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/atopos31/llmio/models"
+	"github.com/tidwall/gjson"
+)
+
+func TestAdminProviderConfig(t *testing.T) {
+	provider := models.Provider{Config: `{"base_urls":["https://a.example.com","https://b.example.com"],"api_keys":[{"key":"sk-first"},{"key":"sk-second"}]}`}
+	provider.ID = 2001
+
+	for range 3 {
+		config, err := AdminProviderConfig(provider)
+		if err != nil {
+			t.Fatalf("AdminProviderConfig() unexpected error: %v", err)
+		}
+		if got := gjson.Get(config, "api_key").String(); got != "sk-first" {
+			t.Errorf("api_key = %q, want sk-first", got)
+		}
+		if got := gjson.Get(config, "base_url").String(); got != "https://a.example.com" {
+			t.Errorf("base_url = %q, want first endpoint", got)
+		}
+	}
+
+	// 管理接口不应推进 Key 池的轮询
+	_, _, key, err := ProviderConfig(provider)
+	if err != nil {
+		t.Fatalf("ProviderConfig() unexpected error: %v", err)
+	}
+	if key != "sk-first" {
+		t.Errorf("first picked key = %q, want sk-first", key)
+	}
+}
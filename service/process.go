@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/atopos31/llmio/consts"
 	"github.com/atopos31/llmio/models"
 	"github.com/tidwall/gjson"
 )
@@ -139,7 +140,7 @@ func ProcesserOpenAiRes(ctx context.Context, pr io.Reader, stream bool, start ti
 			continue
 		}
 		output.OfStringArray = append(output.OfStringArray, content)
-		if event == "response.completed" {
+		if event == "response.completed" || event == "response.incomplete" {
 			usageStr = gjson.Get(content, "response.usage").String()
 		}
 	}
@@ -332,6 +333,348 @@ func ProcesserGemini(ctx context.Context, pr io.Reader, stream bool, start time.
 	}, &output, nil
 }
 
+// ProcesserOllama Ollama 响应为 NDJSON，用量位于 done 为 true 的最后一行
+func ProcesserOllama(ctx context.Context, pr io.Reader, stream bool, start time.Time) (*models.ChatLog, *models.OutputUnion, error) {
+	// 首字时延
+	var firstChunkTime time.Duration
+	var once sync.Once
+
+	var promptTokens, completionTokens int64
+	var output models.OutputUnion
+	var size int
+
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 0, InitScannerBufferSize), MaxScannerBufferSize)
+	for chunk, chunkSize := range ScannerToken(scanner) {
+		size += chunkSize
+		once.Do(func() {
+			firstChunkTime = time.Since(start)
+		})
+		// 流式过程中错误
+		if errStr := gjson.Get(chunk, "error"); errStr.Exists() {
+			return nil, nil, errors.New(errStr.String())
+		}
+		if stream {
+			output.OfStringArray = append(output.OfStringArray, chunk)
+		} else {
+			output.OfString = chunk
+		}
+		if gjson.Get(chunk, "done").Bool() {
+			promptTokens = gjson.Get(chunk, "prompt_eval_count").Int()
+			completionTokens = gjson.Get(chunk, "eval_count").Int()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return &models.ChatLog{
+		FirstChunkTime: firstChunkTime,
+		ChunkTime:      time.Since(start) - firstChunkTime,
+		Usage: models.Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+		Tps:  float64(completionTokens) / time.Since(start).Seconds(),
+		Size: size,
+	}, &output, nil
+}
+
+// ProcesserOpenAIEmbeddings 向量接口只有输入 token
+func ProcesserOpenAIEmbeddings(ctx context.Context, pr io.Reader, stream bool, start time.Time) (*models.ChatLog, *models.OutputUnion, error) {
+	body, err := io.ReadAll(pr)
+	if err != nil {
+		return nil, nil, err
+	}
+	firstChunkTime := time.Since(start)
+
+	var usage models.Usage
+	if usageStr := gjson.GetBytes(body, "usage").Raw; json.Valid([]byte(usageStr)) {
+		if err := json.Unmarshal([]byte(usageStr), &usage); err != nil {
+			return nil, nil, err
+		}
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens
+	}
+
+	return &models.ChatLog{
+		FirstChunkTime: firstChunkTime,
+		Usage:          usage,
+		Size:           len(body),
+	}, &models.OutputUnion{OfString: string(body)}, nil
+}
+
+// ProcesserGeminiEmbeddings Gemini 向量接口的用量位于 usageMetadata，部分模型不返回
+func ProcesserGeminiEmbeddings(ctx context.Context, pr io.Reader, stream bool, start time.Time) (*models.ChatLog, *models.OutputUnion, error) {
+	body, err := io.ReadAll(pr)
+	if err != nil {
+		return nil, nil, err
+	}
+	firstChunkTime := time.Since(start)
+
+	var usage models.Usage
+	usageMetadata := gjson.GetBytes(body, "usageMetadata")
+	usage.PromptTokens = usageMetadata.Get("promptTokenCount").Int()
+	usage.TotalTokens = usageMetadata.Get("totalTokenCount").Int()
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens
+	}
+
+	return &models.ChatLog{
+		FirstChunkTime: firstChunkTime,
+		Usage:          usage,
+		Size:           len(body),
+	}, &models.OutputUnion{OfString: string(body)}, nil
+}
+
+// ProcesserRerank 兼容 Jina 的 usage 与 Cohere 的 meta.billed_units / meta.tokens
+func ProcesserRerank(ctx context.Context, pr io.Reader, stream bool, start time.Time) (*models.ChatLog, *models.OutputUnion, error) {
+	body, err := io.ReadAll(pr)
+	if err != nil {
+		return nil, nil, err
+	}
+	firstChunkTime := time.Since(start)
+
+	var usage models.Usage
+	if u := gjson.GetBytes(body, "usage"); u.Exists() {
+		usage.PromptTokens = u.Get("prompt_tokens").Int()
+		usage.TotalTokens = u.Get("total_tokens").Int()
+	} else if tokens := gjson.GetBytes(body, "meta.tokens"); tokens.Exists() {
+		usage.PromptTokens = tokens.Get("input_tokens").Int()
+		usage.CompletionTokens = tokens.Get("output_tokens").Int()
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	if usage.PromptTokens == 0 {
+		usage.PromptTokens = usage.TotalTokens
+	}
+
+	log := &models.ChatLog{
+		FirstChunkTime: firstChunkTime,
+		Usage:          usage,
+		Size:           len(body),
+	}
+	if searchUnits := gjson.GetBytes(body, "meta.billed_units.search_units"); searchUnits.Exists() {
+		log.Units, log.Unit = searchUnits.Float(), consts.UnitSearchUnits
+	}
+	return log, &models.OutputUnion{OfString: string(body)}, nil
+}
+
+// ProcesserOpenAIImages 记录生成的图片张数，gpt-image 系列额外返回 token 用量
+func ProcesserOpenAIImages(ctx context.Context, pr io.Reader, stream bool, start time.Time) (*models.ChatLog, *models.OutputUnion, error) {
+	// 首字时延
+	var firstChunkTime time.Duration
+	var once sync.Once
+
+	var usageStr string
+	var output models.OutputUnion
+	var size int
+	var images int64
+
+	if !stream {
+		// 非流式响应可能为多行 JSON
+		bodyBytes, err := io.ReadAll(pr)
+		if err != nil {
+			return nil, nil, err
+		}
+		size = len(bodyBytes)
+		firstChunkTime = time.Since(start)
+		output.OfString = string(bodyBytes)
+		usageStr = gjson.GetBytes(bodyBytes, "usage").String()
+		images = gjson.GetBytes(bodyBytes, "data.#").Int()
+	} else {
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 0, InitScannerBufferSize), MaxScannerBufferSize)
+		for chunk, chunkSize := range ScannerToken(scanner) {
+			size += chunkSize
+			once.Do(func() {
+				firstChunkTime = time.Since(start)
+			})
+			after, ok := strings.CutPrefix(chunk, "data: ")
+			if !ok {
+				continue
+			}
+			// 流式过程中错误
+			errStr := gjson.Get(after, "error")
+			if errStr.Exists() {
+				return nil, nil, errors.New(errStr.String())
+			}
+			output.OfStringArray = append(output.OfStringArray, after)
+			// image_generation.completed / image_edit.completed
+			if strings.HasSuffix(gjson.Get(after, "type").String(), ".completed") {
+				images++
+				usageStr = gjson.Get(after, "usage").String()
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var imagesUsage OpenAIResUsage
+	usage := []byte(usageStr)
+	if json.Valid(usage) {
+		if err := json.Unmarshal(usage, &imagesUsage); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	chunkTime := time.Since(start) - firstChunkTime
+
+	return &models.ChatLog{
+		FirstChunkTime: firstChunkTime,
+		ChunkTime:      chunkTime,
+		Usage: models.Usage{
+			PromptTokens:     imagesUsage.InputTokens,
+			CompletionTokens: imagesUsage.OutputTokens,
+			TotalTokens:      imagesUsage.TotalTokens,
+		},
+		Units: float64(images),
+		Unit:  consts.UnitImages,
+		Size:  size,
+	}, &output, nil
+}
+
+// ProcesserOpenAITranscriptions 记录转写音频时长，gpt-4o-transcribe 等模型返回 token 用量
+func ProcesserOpenAITranscriptions(ctx context.Context, pr io.Reader, stream bool, start time.Time) (*models.ChatLog, *models.OutputUnion, error) {
+	// 首字时延
+	var firstChunkTime time.Duration
+	var once sync.Once
+
+	var usage gjson.Result
+	var duration float64
+	var output models.OutputUnion
+	var size int
+
+	if !stream {
+		// 非 JSON 格式(text/srt/vtt)无法获取用量
+		bodyBytes, err := io.ReadAll(pr)
+		if err != nil {
+			return nil, nil, err
+		}
+		size = len(bodyBytes)
+		firstChunkTime = time.Since(start)
+		output.OfString = string(bodyBytes)
+		usage = gjson.GetBytes(bodyBytes, "usage")
+		duration = gjson.GetBytes(bodyBytes, "duration").Float()
+	} else {
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 0, InitScannerBufferSize), MaxScannerBufferSize)
+		for chunk, chunkSize := range ScannerToken(scanner) {
+			size += chunkSize
+			once.Do(func() {
+				firstChunkTime = time.Since(start)
+			})
+			after, ok := strings.CutPrefix(chunk, "data: ")
+			if !ok {
+				continue
+			}
+			// 流式过程中错误
+			errStr := gjson.Get(after, "error")
+			if errStr.Exists() {
+				return nil, nil, errors.New(errStr.String())
+			}
+			output.OfStringArray = append(output.OfStringArray, after)
+			if gjson.Get(after, "type").String() == "transcript.text.done" {
+				usage = gjson.Get(after, "usage")
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	log := &models.ChatLog{
+		FirstChunkTime: firstChunkTime,
+		ChunkTime:      time.Since(start) - firstChunkTime,
+		Size:           size,
+	}
+	// usage.type 为 tokens 或 duration
+	if usage.Get("type").String() == "duration" {
+		duration = usage.Get("seconds").Float()
+	} else {
+		log.Usage = models.Usage{
+			PromptTokens:     usage.Get("input_tokens").Int(),
+			CompletionTokens: usage.Get("output_tokens").Int(),
+			TotalTokens:      usage.Get("total_tokens").Int(),
+			PromptTokensDetails: models.PromptTokensDetails{
+				AudioTokens: usage.Get("input_token_details.audio_tokens").Int(),
+			},
+		}
+	}
+	if duration > 0 {
+		log.Units, log.Unit = duration, consts.UnitSeconds
+	}
+	return log, &output, nil
+}
+
+// ProcesserOpenAISpeech 音频内容不记录，字符数用量由请求侧计算
+func ProcesserOpenAISpeech(ctx context.Context, pr io.Reader, stream bool, start time.Time) (*models.ChatLog, *models.OutputUnion, error) {
+	// 首字时延
+	var firstChunkTime time.Duration
+	var once sync.Once
+
+	var usage gjson.Result
+	var output models.OutputUnion
+	var size int
+
+	if !stream {
+		buf := make([]byte, InitScannerBufferSize)
+		for {
+			n, err := pr.Read(buf)
+			if n > 0 {
+				once.Do(func() {
+					firstChunkTime = time.Since(start)
+				})
+				size += n
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	} else {
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 0, InitScannerBufferSize), MaxScannerBufferSize)
+		for chunk, chunkSize := range ScannerToken(scanner) {
+			size += chunkSize
+			once.Do(func() {
+				firstChunkTime = time.Since(start)
+			})
+			after, ok := strings.CutPrefix(chunk, "data: ")
+			if !ok {
+				continue
+			}
+			// 流式过程中错误
+			errStr := gjson.Get(after, "error")
+			if errStr.Exists() {
+				return nil, nil, errors.New(errStr.String())
+			}
+			if gjson.Get(after, "type").String() == "speech.audio.done" {
+				output.OfStringArray = append(output.OfStringArray, after)
+				usage = gjson.Get(after, "usage")
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &models.ChatLog{
+		FirstChunkTime: firstChunkTime,
+		ChunkTime:      time.Since(start) - firstChunkTime,
+		Usage: models.Usage{
+			PromptTokens:     usage.Get("input_tokens").Int(),
+			CompletionTokens: usage.Get("output_tokens").Int(),
+			TotalTokens:      usage.Get("total_tokens").Int(),
+		},
+		Size: size,
+	}, &output, nil
+}
+
 func ScannerToken(reader *bufio.Scanner) iter.Seq2[string, int] {
 	return func(yield func(string, int) bool) {
 		for reader.Scan() {
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atopos31/llmio/consts"
+)
+
+func TestProcesserEmbeddings(t *testing.T) {
+	tests := []struct {
+		name       string
+		processer  Processer
+		body       string
+		wantPrompt int64
+		wantTotal  int64
+	}{
+		{
+			name:       "openai usage",
+			processer:  ProcesserOpenAIEmbeddings,
+			body:       `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}],"model":"text-embedding-3-small","usage":{"prompt_tokens":8,"total_tokens":8}}`,
+			wantPrompt: 8,
+			wantTotal:  8,
+		},
+		{
+			name:       "openai usage without total",
+			processer:  ProcesserOpenAIEmbeddings,
+			body:       `{"object":"list","data":[],"usage":{"prompt_tokens":5}}`,
+			wantPrompt: 5,
+			wantTotal:  5,
+		},
+		{
+			name:       "gemini usage metadata",
+			processer:  ProcesserGeminiEmbeddings,
+			body:       `{"embedding":{"values":[0.1,0.2]},"usageMetadata":{"promptTokenCount":6,"totalTokenCount":6}}`,
+			wantPrompt: 6,
+			wantTotal:  6,
+		},
+		{
+			name:      "gemini without usage",
+			processer: ProcesserGeminiEmbeddings,
+			body:      `{"embeddings":[{"values":[0.1]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, output, err := tt.processer(context.Background(), strings.NewReader(tt.body), false, time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if log.PromptTokens != tt.wantPrompt || log.TotalTokens != tt.wantTotal {
+				t.Fatalf("usage = %d/%d, want %d/%d", log.PromptTokens, log.TotalTokens, tt.wantPrompt, tt.wantTotal)
+			}
+			if log.Size != len(tt.body) || output.OfString != tt.body {
+				t.Fatalf("unexpected output size=%d", log.Size)
+			}
+		})
+	}
+}
+
+func TestProcesserOpenAITranscriptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		stream      bool
+		wantTokens  int64
+		wantSeconds float64
+	}{
+		{
+			name:        "verbose json duration",
+			body:        `{"task":"transcribe","language":"english","duration":8.47,"text":"hello"}`,
+			wantSeconds: 8.47,
+		},
+		{
+			name:        "duration usage",
+			body:        `{"text":"hello","usage":{"type":"duration","seconds":3}}`,
+			wantSeconds: 3,
+		},
+		{
+			name:       "token usage",
+			body:       `{"text":"hello","usage":{"type":"tokens","input_tokens":14,"output_tokens":45,"total_tokens":59}}`,
+			wantTokens: 59,
+		},
+		{
+			name: "plain text",
+			body: "hello world\n",
+		},
+		{
+			name:       "stream",
+			stream:     true,
+			body:       "data: {\"type\":\"transcript.text.delta\",\"delta\":\"hel\"}\n\ndata: {\"type\":\"transcript.text.done\",\"text\":\"hello\",\"usage\":{\"type\":\"tokens\",\"input_tokens\":4,\"output_tokens\":2,\"total_tokens\":6}}\n\n",
+			wantTokens: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _, err := ProcesserOpenAITranscriptions(context.Background(), strings.NewReader(tt.body), tt.stream, time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if log.TotalTokens != tt.wantTokens {
+				t.Fatalf("total tokens = %d, want %d", log.TotalTokens, tt.wantTokens)
+			}
+			if log.Units != tt.wantSeconds {
+				t.Fatalf("units = %v, want %v", log.Units, tt.wantSeconds)
+			}
+			if tt.wantSeconds > 0 && log.Unit != consts.UnitSeconds {
+				t.Fatalf("unit = %q", log.Unit)
+			}
+		})
+	}
+}
+
+func TestProcesserOpenAISpeech(t *testing.T) {
+	audio := strings.Repeat("\xff\xfb", 10000)
+	log, output, err := ProcesserOpenAISpeech(context.Background(), strings.NewReader(audio), false, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if log.Size != len(audio) {
+		t.Fatalf("size = %d, want %d", log.Size, len(audio))
+	}
+	if output.OfString != "" {
+		t.Fatalf("binary audio should not be recorded")
+	}
+}
+
+func TestProcesserOpenAICompletionsStream(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"id":"cmpl-1","object":"text_completion","choices":[{"text":"    c = a + b","index":0,"finish_reason":null}]}`,
+		``,
+		`data: {"id":"cmpl-1","object":"text_completion","choices":[{"text":"\n","index":0,"finish_reason":"stop"}]}`,
+		``,
+		`data: {"id":"cmpl-1","object":"text_completion","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":7,"total_tokens":19}}`,
+		``,
+		`data: [DONE]`,
+	}, "\n")
+	log, output, err := ProcesserOpenAI(context.Background(), strings.NewReader(body), true, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if log.PromptTokens != 12 || log.CompletionTokens != 7 || log.TotalTokens != 19 {
+		t.Fatalf("unexpected usage: %+v", log.Usage)
+	}
+	if len(output.OfStringArray) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(output.OfStringArray))
+	}
+}
+
+func TestProcesserRerank(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantTokens int64
+		wantUnits  float64
+	}{
+		{
+			name:       "jina usage",
+			body:       `{"model":"jina-reranker-v2-base-multilingual","usage":{"total_tokens":15},"results":[{"index":0,"relevance_score":0.9}]}`,
+			wantTokens: 15,
+		},
+		{
+			name:       "cohere billed units",
+			body:       `{"id":"r1","results":[{"index":0,"relevance_score":0.9}],"meta":{"billed_units":{"search_units":1},"tokens":{"input_tokens":20,"output_tokens":0}}}`,
+			wantTokens: 20,
+			wantUnits:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _, err := ProcesserRerank(context.Background(), strings.NewReader(tt.body), false, time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if log.TotalTokens != tt.wantTokens || log.PromptTokens != tt.wantTokens {
+				t.Fatalf("usage = %+v, want %d tokens", log.Usage, tt.wantTokens)
+			}
+			if log.Units != tt.wantUnits {
+				t.Fatalf("units = %v, want %v", log.Units, tt.wantUnits)
+			}
+		})
+	}
+}
+
+func TestProcesserOllama(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		stream     bool
+		wantChunks int
+	}{
+		{
+			name:   "non stream",
+			body:   `{"model":"llama3.2","message":{"role":"assistant","content":"yes"},"done":true,"done_reason":"stop","prompt_eval_count":26,"eval_count":2}`,
+			stream: false,
+		},
+		{
+			name: "ndjson stream",
+			body: strings.Join([]string{
+				`{"model":"llama3.2","message":{"role":"assistant","content":"y"},"done":false}`,
+				`{"model":"llama3.2","message":{"role":"assistant","content":"es"},"done":false}`,
+				`{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":26,"eval_count":2}`,
+			}, "\n"),
+			stream:     true,
+			wantChunks: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, output, err := ProcesserOllama(context.Background(), strings.NewReader(tt.body), tt.stream, time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if log.PromptTokens != 26 || log.CompletionTokens != 2 || log.TotalTokens != 28 {
+				t.Fatalf("unexpected usage: %+v", log.Usage)
+			}
+			if len(output.OfStringArray) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(output.OfStringArray), tt.wantChunks)
+			}
+		})
+	}
+
+	if _, _, err := ProcesserOllama(context.Background(), strings.NewReader(`{"error":"model not found"}`), true, time.Now()); err == nil {
+		t.Fatal("expected error for error line")
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/converters"
+	"github.com/atopos31/llmio/models"
+	"github.com/tidwall/gjson"
+	"gorm.io/gorm"
+)
+
+// maxConversationDepth previous_response_id 链的最大回溯深度
+const maxConversationDepth = 256
+
+// conversation Responses 请求的会话状态，在 Before 中跨 BalanceChat 与 RecordLog 共享
+type conversation struct {
+	previousID string
+	// previous_response_id 对应的模拟会话历史，nil 表示该 id 来自原生 Responses 提供商
+	history []byte
+	// 成功请求使用的转换器，响应读取完毕后从中获取本轮会话
+	converter converters.Conversational
+}
+
+// emulated 请求是否延续一个通过 chat 提供商模拟的会话
+func (c *conversation) emulated() bool {
+	return c != nil && c.history != nil
+}
+
+// native 请求是否延续一个原生 Responses 提供商的会话
+func (c *conversation) native() bool {
+	return c != nil && c.previousID != "" && c.history == nil
+}
+
+// ResolveConversation 在负载均衡前解析 previous_response_id，
+// 模拟会话只能继续使用 chat 提供商，原生会话只能继续使用 Responses 提供商
+func ResolveConversation(ctx context.Context, style string, before *Before) error {
+	if style != consts.StyleOpenAIRes || before.endpoint != consts.EndpointChat {
+		return nil
+	}
+	before.conversation = &conversation{previousID: gjson.GetBytes(before.raw, "previous_response_id").String()}
+	if before.conversation.previousID == "" {
+		return nil
+	}
+	history, err := loadConversation(ctx, before.conversation.previousID)
+	if err != nil {
+		return err
+	}
+	before.conversation.history = history
+	return nil
+}
+
+// loadConversation 沿 previous_response_id 链加载历史 chat messages，链首不存在时返回 nil
+func loadConversation(ctx context.Context, responseID string) ([]byte, error) {
+	var turns [][]json.RawMessage
+	for id := responseID; id != "" && len(turns) < maxConversationDepth; {
+		conversation, err := gorm.G[models.ResponseConversation](models.DB).Where("response_id = ?", id).First(ctx)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) && id == responseID {
+				return nil, nil
+			}
+			return nil, err
+		}
+		var messages []json.RawMessage
+		if err := json.Unmarshal([]byte(conversation.Messages), &messages); err != nil {
+			return nil, err
+		}
+		turns = append(turns, messages)
+		id = conversation.PreviousResponseID
+	}
+	slices.Reverse(turns)
+	return json.Marshal(slices.Concat(turns...))
+}
+
+// saveConversation 保存模拟会话的本轮上下文，供后续 previous_response_id 使用
+func saveConversation(ctx context.Context, c *conversation) error {
+	if c == nil || c.converter == nil {
+		return nil
+	}
+	turn, ok := c.converter.Conversation()
+	if !ok {
+		return nil
+	}
+	return gorm.G[models.ResponseConversation](models.DB).Create(ctx, &models.ResponseConversation{
+		ResponseID:         turn.ResponseID,
+		PreviousResponseID: turn.PreviousResponseID,
+		Messages:           string(turn.Messages),
+	})
+}
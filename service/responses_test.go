@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/converters"
+	"github.com/atopos31/llmio/models"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+func TestResolveConversation(t *testing.T) {
+	db := setupTestDB(t, &models.Provider{}, &models.Model{}, &models.ModelWithProvider{}, &models.ResponseConversation{})
+	ctx := context.Background()
+
+	chat := models.Provider{Name: "chat", Type: consts.StyleOpenAI, Config: `{}`}
+	native := models.Provider{Name: "native", Type: consts.StyleOpenAIRes, Config: `{}`}
+	model := models.Model{Name: "gpt-res", MaxRetry: 1, TimeOut: 30}
+	lo.Must0(db.Create(&chat).Error)
+	lo.Must0(db.Create(&native).Error)
+	lo.Must0(db.Create(&model).Error)
+	for _, provider := range []models.Provider{chat, native} {
+		lo.Must0(db.Create(&models.ModelWithProvider{ModelID: model.ID, ProviderID: provider.ID, ProviderModel: "gpt-4.1", Status: lo.ToPtr(true), Weight: 1}).Error)
+	}
+
+	// 模拟一轮会话并保存
+	converter := lo.Must(converters.New(consts.StyleOpenAIRes, consts.StyleOpenAI)).(*converters.OpenAIResToOpenAI)
+	lo.Must(converter.Request([]byte(`{"model":"gpt-res","input":"My name is Tom."}`), false))
+	out := lo.Must(converter.Response([]byte(`{"choices":[{"message":{"role":"assistant","content":"Hi Tom."},"finish_reason":"stop"}]}`)))
+	if err := saveConversation(ctx, &conversation{converter: converter}); err != nil {
+		t.Fatalf("saveConversation() unexpected error: %v", err)
+	}
+	responseID := gjson.GetBytes(out, "id").String()
+
+	providerNames := func(raw string) []string {
+		before := lo.Must(BeforerOpenAIRes([]byte(raw)))
+		if err := ResolveConversation(ctx, consts.StyleOpenAIRes, before); err != nil {
+			t.Fatalf("ResolveConversation() unexpected error: %v", err)
+		}
+		meta := lo.Must(ProvidersWithMetaBymodelsName(ctx, consts.StyleOpenAIRes, *before))
+		return lo.Map(lo.Values(meta.ProviderMap), func(p models.Provider, _ int) string { return p.Name })
+	}
+
+	if got := providerNames(`{"model":"gpt-res","input":"hi"}`); len(got) != 2 {
+		t.Errorf("new conversation providers = %v, want both", got)
+	}
+	if got := providerNames(`{"model":"gpt-res","input":"hi","previous_response_id":"` + responseID + `"}`); len(got) != 1 || got[0] != "chat" {
+		t.Errorf("emulated conversation providers = %v, want [chat]", got)
+	}
+	if got := providerNames(`{"model":"gpt-res","input":"hi","previous_response_id":"resp_native"}`); len(got) != 1 || got[0] != "native" {
+		t.Errorf("native conversation providers = %v, want [native]", got)
+	}
+
+	before := lo.Must(BeforerOpenAIRes([]byte(`{"model":"gpt-res","input":"What is my name?","previous_response_id":"` + responseID + `"}`)))
+	if err := ResolveConversation(ctx, consts.StyleOpenAIRes, before); err != nil {
+		t.Fatalf("ResolveConversation() unexpected error: %v", err)
+	}
+	if got := gjson.GetBytes(before.conversation.history, "1.content").String(); got != "Hi Tom." {
+		t.Errorf("history assistant content = %q, want %q", got, "Hi Tom.")
+	}
+}
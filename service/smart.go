@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/atopos31/llmio/balancers"
+	"github.com/atopos31/llmio/consts"
+	"github.com/atopos31/llmio/models"
+	"gorm.io/gorm"
+)
+
+const (
+	smartCacheTTL = 30 * time.Second // 统计结果缓存时长，避免每次请求都扫描日志
+	smartLogLimit = 5000             // 每次统计读取的最近日志数量上限
+)
+
+type smartCache struct {
+	config models.SmartRouting
+	stats  map[string]balancers.SmartStat // key 为 提供商名/提供商模型
+	expiry time.Time
+}
+
+var (
+	smartMu     sync.Mutex
+	smartCaches = make(map[string]smartCache)
+)
+
+// SmartRoutingConfig 读取 smart 策略配置，未配置时返回默认值
+func SmartRoutingConfig(ctx context.Context) (models.SmartRouting, error) {
+	config := models.DefaultSmartRouting
+	record, err := gorm.G[models.Config](models.DB).Where("key = ?", models.KeySmartRouting).First(ctx)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return config, nil
+		}
+		return config, err
+	}
+	if err := json.Unmarshal([]byte(record.Value), &config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// smartWeightItems 按模型近期日志为各关联计算动态权重，失败或未开启时返回静态权重
+func smartWeightItems(ctx context.Context, modelName string, providersWithMeta ProvidersWithMeta) map[uint]int {
+	cache, err := loadSmartCache(ctx, modelName)
+	if err != nil {
+		slog.Error("load smart routing stats error", "error", err)
+		return providersWithMeta.WeightItems
+	}
+	if !cache.config.EnableSmartRouting {
+		return providersWithMeta.WeightItems
+	}
+
+	stats := make(map[uint]balancers.SmartStat, len(providersWithMeta.WeightItems))
+	for id := range providersWithMeta.WeightItems {
+		mp := providersWithMeta.ModelWithProviderMap[id]
+		stats[id] = cache.stats[smartStatKey(providersWithMeta.ProviderMap[mp.ProviderID].Name, mp.ProviderModel)]
+	}
+	return balancers.SmartWeights(providersWithMeta.WeightItems, stats, cache.config.SuccessRateWeight, cache.config.ResponseTimeWeight, cache.config.MinWeight)
+}
+
+func loadSmartCache(ctx context.Context, modelName string) (smartCache, error) {
+	smartMu.Lock()
+	cache, ok := smartCaches[modelName]
+	smartMu.Unlock()
+	if ok && cache.expiry.After(time.Now()) {
+		return cache, nil
+	}
+
+	config, err := SmartRoutingConfig(ctx)
+	if err != nil {
+		return cache, err
+	}
+	cache = smartCache{config: config, expiry: time.Now().Add(smartCacheTTL)}
+	if config.EnableSmartRouting {
+		if cache.stats, err = smartStats(ctx, modelName, time.Duration(config.DecayThresholdHours)*time.Hour); err != nil {
+			return cache, err
+		}
+	}
+
+	smartMu.Lock()
+	smartCaches[modelName] = cache
+	smartMu.Unlock()
+	return cache, nil
+}
+
+func smartStats(ctx context.Context, modelName string, window time.Duration) (map[string]balancers.SmartStat, error) {
+	now := time.Now()
+	logs, err := gorm.G[models.ChatLog](models.DB).
+		Select("provider_name", "provider_model", "status", "first_chunk_time", "created_at").
+		Where("name = ?", modelName).
+		Where("status IN ?", []string{consts.StatusSuccess, consts.StatusError}).
+		Where("created_at > ?", now.Add(-window)).
+		Order("id DESC").
+		Limit(smartLogLimit).
+		Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]balancers.SmartStat)
+	for _, log := range logs {
+		key := smartStatKey(log.ProviderName, log.ProviderModel)
+		stat := stats[key]
+		stat.Add(now.Sub(log.CreatedAt), window, log.Status == consts.StatusSuccess, log.FirstChunkTime)
+		stats[key] = stat
+	}
+	return stats, nil
+}
+
+func smartStatKey(providerName, providerModel string) string {
+	return providerName + "/" + providerModel
+}
+
+// ResetSmartRoutingCache 配置变更后清空统计缓存，使新配置立即生效
+func ResetSmartRoutingCache() {
+	smartMu.Lock()
+	defer smartMu.Unlock()
+	clear(smartCaches)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/atopos31/llmio/consts"
+	"github.com/tidwall/gjson"
+)
+
+// sticky 策略的会话标识来源，模型未配置时依次尝试请求体中的用户标识与提示词哈希
+const (
+	SessionKeyHeaderPrefix = "header:" // header:X-Session-Id 取请求头
+	SessionKeyBodyPrefix   = "body:"   // body:metadata.user_id 取请求体 gjson 路径
+	SessionKeyPrompt       = "prompt"  // 系统提示词与首条消息的哈希
+)
+
+// sessionUserPaths 各协议请求体中常见的会话/用户标识
+var sessionUserPaths = []string{"metadata.user_id", "prompt_cache_key", "user"}
+
+// sessionPromptPaths 各协议的系统提示词与首条消息位置
+var sessionPromptPaths = map[consts.Style][2]string{
+	consts.StyleOpenAI:    {`messages.#(role=="system").content`, `messages.#(role!="system").content`},
+	consts.StyleOllama:    {`messages.#(role=="system").content`, `messages.#(role!="system").content`},
+	consts.StyleAnthropic: {"system", "messages.0.content"},
+	consts.StyleGemini:    {"systemInstruction", "contents.0"},
+	consts.StyleOpenAIRes: {"instructions", "input.0"},
+}
+
+// sessionID 按 sessionKey 从请求中提取会话标识，取不到时返回空字符串
+func sessionID(sessionKey string, style consts.Style, raw []byte, header http.Header) string {
+	switch {
+	case strings.HasPrefix(sessionKey, SessionKeyHeaderPrefix):
+		return header.Get(strings.TrimPrefix(sessionKey, SessionKeyHeaderPrefix))
+	case strings.HasPrefix(sessionKey, SessionKeyBodyPrefix):
+		return gjson.GetBytes(raw, strings.TrimPrefix(sessionKey, SessionKeyBodyPrefix)).String()
+	case sessionKey == SessionKeyPrompt:
+		return promptHash(style, raw)
+	}
+	for _, path := range sessionUserPaths {
+		if id := gjson.GetBytes(raw, path).String(); id != "" {
+			return id
+		}
+	}
+	return promptHash(style, raw)
+}
+
+// promptHash 同一会话的后续请求保留相同的系统提示词与首条消息，以其哈希作为会话标识
+func promptHash(style consts.Style, raw []byte) string {
+	paths, ok := sessionPromptPaths[style]
+	if !ok {
+		return ""
+	}
+	first := gjson.GetBytes(raw, paths[1])
+	if input := gjson.GetBytes(raw, "input"); style == consts.StyleOpenAIRes && input.Type == gjson.String {
+		first = input
+	}
+	if !first.Exists() {
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(gjson.GetBytes(raw, paths[0]).Raw))
+	h.Write([]byte{0})
+	h.Write([]byte(first.Raw))
+	return hex.EncodeToString(h.Sum(nil))
+}
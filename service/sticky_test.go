@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/atopos31/llmio/consts"
+)
+
+func TestSessionID(t *testing.T) {
+	header := http.Header{"X-Session-Id": []string{"s-1"}}
+	anthropic := []byte(`{"system":"be brief","metadata":{"user_id":"u-1"},"messages":[{"role":"user","content":"hi"}]}`)
+
+	tests := []struct {
+		name       string
+		sessionKey string
+		style      consts.Style
+		raw        []byte
+		want       string
+	}{
+		{"header", "header:X-Session-Id", consts.StyleAnthropic, anthropic, "s-1"},
+		{"body path", "body:metadata.user_id", consts.StyleAnthropic, anthropic, "u-1"},
+		{"auto user id", "", consts.StyleAnthropic, anthropic, "u-1"},
+		{"missing header", "header:X-Other", consts.StyleAnthropic, anthropic, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sessionID(tt.sessionKey, tt.style, tt.raw, header); got != tt.want {
+				t.Fatalf("sessionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionIDPromptHash(t *testing.T) {
+	first := []byte(`{"messages":[{"role":"system","content":"sys"},{"role":"user","content":"hello"}]}`)
+	next := []byte(`{"messages":[{"role":"system","content":"sys"},{"role":"user","content":"hello"},{"role":"assistant","content":"hi"},{"role":"user","content":"more"}]}`)
+	other := []byte(`{"messages":[{"role":"system","content":"sys"},{"role":"user","content":"bye"}]}`)
+
+	a := sessionID(SessionKeyPrompt, consts.StyleOpenAI, first, http.Header{})
+	if a == "" {
+		t.Fatal("expected prompt hash")
+	}
+	if b := sessionID("", consts.StyleOpenAI, next, http.Header{}); b != a {
+		t.Fatalf("follow-up turn hash %q differs from %q", b, a)
+	}
+	if c := sessionID("", consts.StyleOpenAI, other, http.Header{}); c == a {
+		t.Fatal("different conversations share a hash")
+	}
+	if d := sessionID("", consts.StyleOpenAIRes, []byte(`{"input":"hello"}`), http.Header{}); d == "" {
+		t.Fatal("expected hash for string input")
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/atopos31/llmio/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupTestDB 使用内存 SQLite 替换 models.DB 并迁移 tables，测试结束后恢复
+func setupTestDB(t *testing.T, tables ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	// 内存数据库每个连接独立，限制为单连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	previous := models.DB
+	models.DB = db
+	t.Cleanup(func() { models.DB = previous })
+	return db
+}